@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSignedBodySize caps how much of a request body is read to check its
+// signature.
+const maxSignedBodySize = 1 << 20
+
+// signatureMaxAge is how far a signed request's timestamp may be from now,
+// either way, before it's refused as a replay.
+const signatureMaxAge = 5 * time.Minute
+
+// Authenticator guards endpoints that change server state. Requests must
+// carry either the configured bearer token or an X-Signature-256 header
+// holding the hex HMAC-SHA256 ("sha256=...") of the X-Signature-Timestamp
+// header, a dot and the body. The timestamp is in Unix seconds and must be
+// within signatureMaxAge of now, so a captured request can't be replayed
+// later.
+type Authenticator struct {
+	token  string
+	secret []byte
+	now    func() time.Time
+}
+
+func NewAuthenticator(token, hmacSecret string) *Authenticator {
+	a := &Authenticator{token: token, now: time.Now}
+	if hmacSecret != "" {
+		a.secret = []byte(hmacSecret)
+	}
+	return a
+}
+
+// Enabled reports whether any credentials are configured. Without them every
+// request is refused.
+func (a *Authenticator) Enabled() bool {
+	return a.token != "" || a.secret != nil
+}
+
+func (a *Authenticator) Require(next http.HandlerFunc) http.HandlerFunc {
+	return a.require(next, false)
+}
+
+// RequireBasic is Require that also takes the token as the password of HTTP
+// Basic auth, with any username, for clients such as OwnTracks that can't
+// send a bearer token.
+func (a *Authenticator) RequireBasic(next http.HandlerFunc) http.HandlerFunc {
+	return a.require(next, true)
+}
+
+func (a *Authenticator) require(next http.HandlerFunc, basic bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.Enabled() {
+			writeJSONError(w, http.StatusForbidden, "endpoint disabled: no credentials configured")
+			return
+		}
+
+		if a.token != "" {
+			if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && a.validToken(token) {
+				next(w, withCredential(r, credentialToken))
+				return
+			}
+			if _, password, ok := r.BasicAuth(); ok && basic && a.validToken(password) {
+				next(w, withCredential(r, credentialToken))
+				return
+			}
+		}
+
+		if a.secret != nil && r.Header.Get("X-Signature-256") != "" {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize))
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "failed to read body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if a.validTimestamp(r.Header.Get("X-Signature-Timestamp")) {
+				signed := r.Header.Get("X-Signature-Timestamp") + "." + string(body)
+				if validSignature(a.secret, []byte(signed), r.Header.Get("X-Signature-256")) {
+					next(w, withCredential(r, credentialSignature))
+					return
+				}
+			}
+		}
+
+		if basic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="joeburgess.dev"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="joeburgess.dev"`)
+		}
+		writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+	}
+}
+
+// Credentials an authenticated request can have used.
+const (
+	credentialToken     = "token"
+	credentialSignature = "signature"
+)
+
+type credentialKey struct{}
+
+func withCredential(r *http.Request, credential string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), credentialKey{}, credential))
+}
+
+// credential names the credential an Authenticator accepted r with, or ""
+// if r didn't pass through one.
+func credential(r *http.Request) string {
+	c, _ := r.Context().Value(credentialKey{}).(string)
+	return c
+}
+
+func (a *Authenticator) validToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) == 1
+}
+
+// validTimestamp checks a signed request's Unix timestamp is recent.
+func (a *Authenticator) validTimestamp(timestamp string) bool {
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := a.now().Sub(time.Unix(secs, 0))
+	return age.Abs() <= signatureMaxAge
+}
+
+// validSignature checks a "sha256=<hex>" HMAC signature of body.
+func validSignature(secret, body []byte, signature string) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signRequest signs body the way Authenticator expects, at timestamp.
+func signRequest(secret string, timestamp time.Time, body string) map[string]string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return map[string]string{
+		"X-Signature-Timestamp": ts,
+		"X-Signature-256":       sign(secret, ts+"."+body),
+	}
+}
+
+func TestAuthenticatorRequire(t *testing.T) {
+	const token = "0123456789abcdef-token"
+	const secret = "0123456789abcdef-secret"
+	const body = `{"reason":"deploy"}`
+	now := time.Date(2024, 4, 25, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		auth    *Authenticator
+		basic   bool
+		headers map[string]string
+		want    int
+	}{
+		{"disabled", NewAuthenticator("", ""), false, map[string]string{"Authorization": "Bearer " + token}, http.StatusForbidden},
+		{"no credentials", NewAuthenticator(token, secret), false, nil, http.StatusUnauthorized},
+		{"valid token", NewAuthenticator(token, ""), false, map[string]string{"Authorization": "Bearer " + token}, http.StatusOK},
+		{"wrong token", NewAuthenticator(token, ""), false, map[string]string{"Authorization": "Bearer nope"}, http.StatusUnauthorized},
+		{"token without bearer prefix", NewAuthenticator(token, ""), false, map[string]string{"Authorization": token}, http.StatusUnauthorized},
+		{"basic auth where only bearer is allowed", NewAuthenticator(token, ""), false, map[string]string{"Authorization": basicAuth("joe", token)}, http.StatusUnauthorized},
+		{"basic auth with token as password", NewAuthenticator(token, ""), true, map[string]string{"Authorization": basicAuth("joe", token)}, http.StatusOK},
+		{"basic auth with wrong password", NewAuthenticator(token, ""), true, map[string]string{"Authorization": basicAuth("joe", "nope")}, http.StatusUnauthorized},
+		{"bearer token where basic is allowed", NewAuthenticator(token, ""), true, map[string]string{"Authorization": "Bearer " + token}, http.StatusOK},
+		{"valid signature", NewAuthenticator("", secret), false, signRequest(secret, now, body), http.StatusOK},
+		{"signature a few minutes old", NewAuthenticator("", secret), false, signRequest(secret, now.Add(-4*time.Minute), body), http.StatusOK},
+		{"replayed signature", NewAuthenticator("", secret), false, signRequest(secret, now.Add(-time.Hour), body), http.StatusUnauthorized},
+		{"signature from the future", NewAuthenticator("", secret), false, signRequest(secret, now.Add(time.Hour), body), http.StatusUnauthorized},
+		{"signature without timestamp", NewAuthenticator("", secret), false, map[string]string{"X-Signature-256": sign(secret, body)}, http.StatusUnauthorized},
+		{"timestamp changed after signing", NewAuthenticator("", secret), false, map[string]string{
+			"X-Signature-Timestamp": strconv.FormatInt(now.Unix(), 10),
+			"X-Signature-256":       signRequest(secret, now.Add(-time.Hour), body)["X-Signature-256"],
+		}, http.StatusUnauthorized},
+		{"signature from wrong secret", NewAuthenticator("", secret), false, signRequest("other", now, body), http.StatusUnauthorized},
+		{"malformed signature", NewAuthenticator("", secret), false, map[string]string{"X-Signature-Timestamp": strconv.FormatInt(now.Unix(), 10), "X-Signature-256": "md5=abc"}, http.StatusUnauthorized},
+		{"signature when only token configured", NewAuthenticator(token, ""), false, signRequest(secret, now, body), http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.auth.now = func() time.Time { return now }
+			var gotBody string
+			next := func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				gotBody = string(b)
+			}
+			handler := tc.auth.Require(next)
+			if tc.basic {
+				handler = tc.auth.RequireBasic(next)
+			}
+
+			req := httptest.NewRequest("POST", "/update-data", strings.NewReader(body))
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+
+			if rr.Code != tc.want {
+				t.Fatalf("got status %d, want %d", rr.Code, tc.want)
+			}
+			if rr.Code == http.StatusOK && gotBody != body {
+				t.Errorf("handler got body %q, want %q", gotBody, body)
+			}
+			if rr.Code == http.StatusUnauthorized && tc.basic && !strings.HasPrefix(rr.Header().Get("WWW-Authenticate"), "Basic ") {
+				t.Errorf("WWW-Authenticate = %q, want a Basic challenge", rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
@@ -21,8 +21,9 @@ func (h *GithubHandler) HandleGithubData(w http.ResponseWriter, r *http.Request)
 	data := h.dataUpdater.GetData()
 
 	apiData := map[string]any{
-		"repos":      data.GithubRepos,
-		"activities": data.GitHubActivities,
+		"repos":      data.Repos,
+		"activities": data.Activities,
+		"stats":      data.Stats,
 		"updated":    data.LastUpdated,
 	}
 
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/josephburgess/joeburgess.dev/internal/templates"
 )
 
 func TestHandleNotFound(t *testing.T) {
@@ -21,3 +25,59 @@ func TestHandleNotFound(t *testing.T) {
 		t.Errorf("got status %d, want %d", rr.Code, http.StatusNotFound)
 	}
 }
+
+func TestHandleUpdateData(t *testing.T) {
+	du := templates.NewDataUpdater("", nil)
+	handler := NewHomeHandler(nil, du)
+
+	rr := httptest.NewRecorder()
+	handler.HandleUpdateData(rr, httptest.NewRequest("POST", "/update-data", nil))
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusAccepted)
+	}
+
+	var resp updateResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID == "" {
+		t.Fatal("response has no update id")
+	}
+	if got, want := rr.Header().Get("Location"), "/update-data/"+resp.ID; got != want {
+		t.Errorf("got Location %q, want %q", got, want)
+	}
+
+	if err := du.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/update-data/"+resp.ID, nil)
+	req.SetPathValue("id", resp.ID)
+	rr = httptest.NewRecorder()
+	handler.HandleUpdateStatus(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusOK)
+	}
+	var status templates.UpdateStatus
+	if err := json.NewDecoder(rr.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.ID != resp.ID || status.Status == templates.UpdateRunning {
+		t.Errorf("got status %+v, want finished update %s", status, resp.ID)
+	}
+}
+
+func TestHandleUpdateStatusUnknownID(t *testing.T) {
+	handler := NewHomeHandler(nil, templates.NewDataUpdater("", nil))
+
+	req := httptest.NewRequest("GET", "/update-data/nope", nil)
+	req.SetPathValue("id", "nope")
+	rr := httptest.NewRecorder()
+	handler.HandleUpdateStatus(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
@@ -26,6 +26,12 @@ func (h *HomeHandler) HandleHome(w http.ResponseWriter, r *http.Request) {
 		data.IsDarkMode = (cookie.Value == "dark")
 	}
 
+	data.Units = visitorUnits(w, r)
+	if data.Weather != nil {
+		weather := data.Weather.In(data.Units)
+		data.Weather = &weather
+	}
+
 	html, err := h.renderer.RenderTemplate(&data)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -33,14 +39,32 @@ func (h *HomeHandler) HandleHome(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Vary", "Cookie, Accept-Language")
 	w.Write([]byte(html))
 }
 
+type updateResponse struct {
+	templates.UpdateStatus
+	Coalesced bool `json:"coalesced"`
+}
+
+// HandleUpdateData starts a data update, or joins the one already running,
+// and returns its id so callers can poll HandleUpdateStatus.
 func (h *HomeHandler) HandleUpdateData(w http.ResponseWriter, r *http.Request) {
-	go h.dataUpdater.Update()
+	status, coalesced := h.dataUpdater.TriggerUpdate()
+
+	w.Header().Set("Location", "/update-data/"+status.ID)
+	writeJSON(w, http.StatusAccepted, updateResponse{UpdateStatus: status, Coalesced: coalesced})
+}
+
+func (h *HomeHandler) HandleUpdateStatus(w http.ResponseWriter, r *http.Request) {
+	status, ok := h.dataUpdater.UpdateStatus(r.PathValue("id"))
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "unknown update id")
+		return
+	}
 
-	w.WriteHeader(http.StatusAccepted)
-	w.Write([]byte("Data update triggered"))
+	writeJSON(w, http.StatusOK, status)
 }
 
 func (h *HomeHandler) HandleNotFound(w http.ResponseWriter, r *http.Request) {
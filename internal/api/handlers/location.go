@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/templates"
+)
+
+// maxLocationBodySize is far more than any location update needs. OwnTracks
+// payloads are a few hundred bytes.
+const maxLocationBodySize = 64 << 10
+
+// LocationStore keeps the location the weather is reported for.
+type LocationStore interface {
+	Current() models.Location
+	Set(models.Location) (bool, error)
+}
+
+type LocationHandler struct {
+	locations   LocationStore
+	dataUpdater *templates.DataUpdater
+	now         func() time.Time
+}
+
+func NewLocationHandler(locations LocationStore, dataUpdater *templates.DataUpdater) *LocationHandler {
+	return &LocationHandler{
+		locations:   locations,
+		dataUpdater: dataUpdater,
+		now:         time.Now,
+	}
+}
+
+// locationUpdate is either a city or a lat/lon pair, or both. It also
+// decodes OwnTracks HTTP payloads, which carry a "_type". OwnTracks can only
+// authenticate with HTTP Basic auth, so the route is guarded with
+// Authenticator.RequireBasic.
+type locationUpdate struct {
+	Type string   `json:"_type"`
+	City string   `json:"city"`
+	Lat  *float64 `json:"lat"`
+	Lon  *float64 `json:"lon"`
+}
+
+func (u locationUpdate) location() (models.Location, string) {
+	loc := models.Location{Name: strings.TrimSpace(u.City)}
+
+	if (u.Lat == nil) != (u.Lon == nil) {
+		return loc, "lat and lon must be sent together"
+	}
+	if u.Lat != nil {
+		c := models.Coordinates{Lat: *u.Lat, Lon: *u.Lon}
+		if !c.Valid() {
+			return loc, "lat or lon out of range"
+		}
+		loc.Coordinates = &c
+	}
+
+	if loc.IsZero() {
+		return loc, "city or lat and lon required"
+	}
+	return loc, ""
+}
+
+// HandleLocationUpdate sets the location the weather widget reports for and
+// refreshes the weather when it moves. Coordinates are coarsened to city
+// level before they're stored or shown.
+func (h *LocationHandler) HandleLocationUpdate(w http.ResponseWriter, r *http.Request) {
+	var update locationUpdate
+	err := json.NewDecoder(io.LimitReader(r.Body, maxLocationBodySize)).Decode(&update)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	// OwnTracks also posts waypoints, transitions and the like, and expects
+	// a JSON array back whatever it sent.
+	if update.Type != "" {
+		if update.Type == "location" {
+			if !h.update(w, update) {
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, []any{})
+		return
+	}
+
+	if h.update(w, update) {
+		writeJSON(w, http.StatusOK, map[string]any{"location": h.locations.Current()})
+	}
+}
+
+// update stores the location in u, writing an error response and returning
+// false if it can't.
+func (h *LocationHandler) update(w http.ResponseWriter, u locationUpdate) bool {
+	loc, problem := u.location()
+	if problem != "" {
+		writeJSONError(w, http.StatusBadRequest, problem)
+		return false
+	}
+	loc.UpdatedAt = h.now()
+
+	changed, err := h.locations.Set(loc)
+	if err != nil {
+		logging.Error("Failed to save location", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to save location")
+		return false
+	}
+
+	if changed {
+		logging.Info("Location changed to %s", h.locations.Current())
+		h.dataUpdater.Refresh(models.KeyWeather)
+	}
+	return true
+}
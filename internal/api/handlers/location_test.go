@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/weather"
+	"github.com/josephburgess/joeburgess.dev/internal/templates"
+)
+
+func newLocationHandler(t *testing.T) (*LocationHandler, *weather.Locations, *countingSource) {
+	t.Helper()
+	locations, err := weather.NewLocations("", models.Location{Name: "London, GB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := &countingSource{key: models.KeyWeather}
+	du := templates.NewDataUpdater("", nil)
+	du.Register(src)
+	du.Update(context.Background())
+	t.Cleanup(func() { du.Shutdown(context.Background()) })
+
+	h := NewLocationHandler(locations, du)
+	h.now = func() time.Time { return time.Date(2025, 5, 1, 9, 30, 0, 0, time.UTC) }
+	return h, locations, src
+}
+
+func postLocation(h *LocationHandler, body string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.HandleLocationUpdate(rr, httptest.NewRequest("POST", "/api/location", strings.NewReader(body)))
+	return rr
+}
+
+func waitForFetches(t *testing.T, src *countingSource, want int32) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for src.calls.Load() < want {
+		if time.Now().After(deadline) {
+			t.Fatal("weather was not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHandleLocationUpdateCity(t *testing.T) {
+	h, locations, src := newLocationHandler(t)
+
+	rr := postLocation(h, `{"city": " Lisbon, PT "}`)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", rr.Code, http.StatusOK, rr.Body)
+	}
+	var resp struct {
+		Location models.Location `json:"location"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Location.Name != "Lisbon, PT" {
+		t.Errorf("got location %q, want Lisbon, PT", resp.Location.Name)
+	}
+	if got := locations.Current(); got.Name != "Lisbon, PT" || got.UpdatedAt.IsZero() {
+		t.Errorf("stored location %+v, want Lisbon, PT with an update time", got)
+	}
+	waitForFetches(t, src, 2)
+}
+
+func TestHandleLocationUpdateCoordinates(t *testing.T) {
+	h, locations, _ := newLocationHandler(t)
+
+	rr := postLocation(h, `{"lat": 38.722252, "lon": -9.139337}`)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", rr.Code, http.StatusOK, rr.Body)
+	}
+	if strings.Contains(rr.Body.String(), "38.72") {
+		t.Errorf("response leaks precise coordinates: %s", rr.Body)
+	}
+	got := locations.Current()
+	if got.Coordinates == nil || *got.Coordinates != (models.Coordinates{Lat: 38.7, Lon: -9.1}) {
+		t.Errorf("stored coordinates %+v, want 38.7,-9.1", got.Coordinates)
+	}
+}
+
+func TestHandleLocationUpdateOwnTracks(t *testing.T) {
+	h, locations, _ := newLocationHandler(t)
+
+	rr := postLocation(h, `{"_type": "location", "lat": 48.8566, "lon": 2.3522, "tst": 1714555800, "acc": 12}`)
+
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Fatalf("got %d %s, want 200 []", rr.Code, rr.Body)
+	}
+	if got := locations.Current().Coordinates; got == nil || *got != (models.Coordinates{Lat: 48.9, Lon: 2.4}) {
+		t.Errorf("stored coordinates %+v, want 48.9,2.4", got)
+	}
+
+	rr = postLocation(h, `{"_type": "transition", "event": "leave"}`)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("got status %d for a transition, want %d", rr.Code, http.StatusOK)
+	}
+	if got := locations.Current().Coordinates; got == nil || got.Lat != 48.9 {
+		t.Errorf("transition changed the location to %+v", got)
+	}
+}
+
+func TestHandleLocationUpdateUnchangedSkipsRefresh(t *testing.T) {
+	h, _, src := newLocationHandler(t)
+
+	postLocation(h, `{"city": "London, GB"}`)
+	h.dataUpdater.Shutdown(context.Background())
+
+	if got := src.calls.Load(); got != 1 {
+		t.Errorf("weather fetched %d times, want 1", got)
+	}
+}
+
+func TestHandleLocationUpdateRejectsBadInput(t *testing.T) {
+	tests := map[string]string{
+		"not JSON":      `city=London`,
+		"empty":         `{}`,
+		"blank city":    `{"city": "  "}`,
+		"lat only":      `{"lat": 51.5}`,
+		"out of range":  `{"lat": 91, "lon": 0}`,
+		"owntracks bad": `{"_type": "location", "lat": 51.5}`,
+	}
+
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			h, locations, _ := newLocationHandler(t)
+
+			rr := postLocation(h, body)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+			}
+			if got := locations.Current(); got.Name != "London, GB" {
+				t.Errorf("location changed to %+v", got)
+			}
+		})
+	}
+}
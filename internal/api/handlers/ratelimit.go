@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a per-client token bucket. Each client may make burst
+// requests at once, then one more every interval.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	clients  map[string]*bucket
+	now      func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(interval time.Duration, burst int) *RateLimiter {
+	return &RateLimiter{
+		interval: interval,
+		burst:    burst,
+		clients:  make(map[string]*bucket),
+		now:      time.Now,
+	}
+}
+
+// Allow takes a token for client if one is available. Otherwise it returns
+// how long until the next token.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	b, ok := l.clients[client]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.clients[client] = b
+	}
+
+	b.tokens = min(float64(l.burst), b.tokens+float64(now.Sub(b.last))/float64(l.interval))
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(l.interval))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune drops clients whose buckets have refilled, since they're
+// indistinguishable from new clients.
+func (l *RateLimiter) prune(now time.Time) {
+	full := l.interval * time.Duration(l.burst)
+	for client, b := range l.clients {
+		if now.Sub(b.last) >= full {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// Limit refuses requests once their client's bucket is empty. Requests an
+// Authenticator let through are limited per credential, so wrap Limit in
+// Authenticator.Require: anonymous requests are refused before they can use
+// up the bucket, and behind a reverse proxy, where every request comes from
+// the same address, each credential still gets its own. Anything else is
+// limited per remote address.
+func (l *RateLimiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		client := "ip:" + clientIP(r)
+		if c := credential(r); c != "" {
+			client = "credential:" + c
+		}
+		ok, wait := l.Allow(client)
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
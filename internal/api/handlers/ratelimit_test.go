@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(time.Minute, 2)
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if ok, _ := l.Allow("1.2.3.4"); !ok {
+			t.Fatalf("request %d within burst was refused", i+1)
+		}
+	}
+
+	ok, wait := l.Allow("1.2.3.4")
+	if ok {
+		t.Fatal("request beyond burst was allowed")
+	}
+	if wait != time.Minute {
+		t.Errorf("got wait %s, want %s", wait, time.Minute)
+	}
+
+	if ok, _ := l.Allow("5.6.7.8"); !ok {
+		t.Error("other clients should have their own bucket")
+	}
+
+	now = now.Add(30 * time.Second)
+	if ok, wait := l.Allow("1.2.3.4"); ok || wait != 30*time.Second {
+		t.Errorf("got ok=%v wait=%s after half an interval, want ok=false wait=30s", ok, wait)
+	}
+
+	now = now.Add(30 * time.Second)
+	if ok, _ := l.Allow("1.2.3.4"); !ok {
+		t.Error("a token should be available after a full interval")
+	}
+}
+
+func TestRateLimiterPrunesRefilledClients(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(time.Minute, 2)
+	l.now = func() time.Time { return now }
+
+	l.Allow("1.2.3.4")
+	now = now.Add(2 * time.Minute)
+	l.Allow("5.6.7.8")
+
+	if _, ok := l.clients["1.2.3.4"]; ok {
+		t.Error("refilled client was not pruned")
+	}
+}
+
+func TestRateLimiterLimit(t *testing.T) {
+	l := NewRateLimiter(time.Hour, 1)
+	handler := l.Limit(func(w http.ResponseWriter, r *http.Request) {})
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest("POST", "/update-data", nil)
+		req.RemoteAddr = "1.2.3.4:5678"
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+
+		if rr.Code != want {
+			t.Errorf("request %d: got status %d, want %d", i+1, rr.Code, want)
+		}
+	}
+}
+
+func TestRateLimiterLimitsAfterAuthentication(t *testing.T) {
+	const token = "0123456789abcdef-token"
+	auth := NewAuthenticator(token, "")
+	l := NewRateLimiter(time.Hour, 1)
+	handler := auth.Require(l.Limit(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(authorization string) int {
+		req := httptest.NewRequest("POST", "/update-data", nil)
+		// Behind the reverse proxy every request comes from the same address.
+		req.RemoteAddr = "172.18.0.2:5678"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr.Code
+	}
+
+	for i := range 5 {
+		if got := send(""); got != http.StatusUnauthorized {
+			t.Fatalf("anonymous request %d: got status %d, want %d", i+1, got, http.StatusUnauthorized)
+		}
+	}
+	if got := send("Bearer " + token); got != http.StatusOK {
+		t.Fatalf("anonymous requests used up the bucket: got status %d, want %d", got, http.StatusOK)
+	}
+	if got := send("Bearer " + token); got != http.StatusTooManyRequests {
+		t.Errorf("second authenticated request: got status %d, want %d", got, http.StatusTooManyRequests)
+	}
+}
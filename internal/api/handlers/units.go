@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+const unitsCookie = "units"
+
+// imperialRegions are the regions that give temperatures in Fahrenheit.
+var imperialRegions = []string{"US", "PR", "GU", "VI", "AS", "MP", "UM", "BS", "BZ", "KY", "PW", "FM", "MH", "LR"}
+
+// visitorUnits picks the units to show a visitor the weather in: a units
+// query parameter, as set by the toggle, which is also remembered in a
+// cookie; then that cookie; then the region of their preferred language.
+func visitorUnits(w http.ResponseWriter, r *http.Request) models.Units {
+	if units, ok := models.ParseUnits(r.URL.Query().Get("units")); ok {
+		http.SetCookie(w, &http.Cookie{
+			Name:     unitsCookie,
+			Value:    string(units),
+			Path:     "/",
+			MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+			SameSite: http.SameSiteLaxMode,
+		})
+		return units
+	}
+
+	if cookie, err := r.Cookie(unitsCookie); err == nil {
+		if units, ok := models.ParseUnits(cookie.Value); ok {
+			return units
+		}
+	}
+
+	if slices.Contains(imperialRegions, languageRegion(r.Header.Get("Accept-Language"))) {
+		return models.Imperial
+	}
+	return models.Metric
+}
+
+// languageRegion returns the upper-cased region of the most preferred
+// language in an Accept-Language header, such as "US" for
+// "en-US,en;q=0.9", or "" if it doesn't name one.
+func languageRegion(header string) string {
+	var best string
+	bestQ := -1.0
+	for entry := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if tag != "" && q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+
+	// The region is the first two-letter subtag after the language, as in
+	// en-US or zh-Hant-TW.
+	subtags := strings.Split(best, "-")
+	for _, s := range subtags[min(1, len(subtags)):] {
+		if len(s) == 2 {
+			return strings.ToUpper(s)
+		}
+	}
+	return ""
+}
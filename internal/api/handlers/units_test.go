@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/templates"
+)
+
+func TestLanguageRegion(t *testing.T) {
+	tests := map[string]string{
+		"en-US,en;q=0.9":              "US",
+		"en-GB":                       "GB",
+		"fr;q=0.8, en-us;q=0.9":       "US",
+		"zh-Hant-TW":                  "TW",
+		"en":                          "",
+		"":                            "",
+		"*":                           "",
+		"de-DE;q=bad, es-419;q=0.5":   "",
+		"en-US;q=0.1, pt-BR;q=0.2, *": "",
+	}
+
+	for header, want := range tests {
+		if got := languageRegion(header); got != want {
+			t.Errorf("languageRegion(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestVisitorUnits(t *testing.T) {
+	tests := map[string]struct {
+		query, cookie, language string
+		want                    models.Units
+	}{
+		"default":            {want: models.Metric},
+		"british":            {language: "en-GB,en;q=0.9", want: models.Metric},
+		"american":           {language: "en-US,en;q=0.9", want: models.Imperial},
+		"cookie wins":        {cookie: "metric", language: "en-US", want: models.Metric},
+		"bad cookie ignored": {cookie: "kelvin", language: "en-US", want: models.Imperial},
+		"toggle wins":        {query: "imperial", cookie: "metric", want: models.Imperial},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?units="+tc.query, nil)
+			if tc.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "units", Value: tc.cookie})
+			}
+			if tc.language != "" {
+				r.Header.Set("Accept-Language", tc.language)
+			}
+			rr := httptest.NewRecorder()
+
+			if got := visitorUnits(rr, r); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+
+			setCookie := rr.Header().Get("Set-Cookie")
+			if tc.query != "" && !strings.HasPrefix(setCookie, "units="+tc.query) {
+				t.Errorf("toggle didn't remember the choice, Set-Cookie: %q", setCookie)
+			}
+			if tc.query == "" && setCookie != "" {
+				t.Errorf("unexpected Set-Cookie: %q", setCookie)
+			}
+		})
+	}
+}
+
+func TestHandleHomeShowsVisitorUnits(t *testing.T) {
+	t.Chdir("../../..")
+	du := templates.NewDataUpdater("", nil)
+	du.Register(&weatherSource{weather: &models.WeatherData{Location: "London", Temperature: 20, WindSpeed: 10}})
+	du.Update(context.Background())
+	handler := NewHomeHandler(templates.NewRenderer(), du)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "en-US")
+	rr := httptest.NewRecorder()
+	handler.HandleHome(rr, r)
+
+	body := rr.Body.String()
+	if !strings.Contains(body, "68°F") || !strings.Contains(body, "22 mph") {
+		t.Errorf("weather isn't in imperial units:\n%s", body)
+	}
+	if !strings.Contains(body, `href="/?units=metric"`) {
+		t.Error("no toggle back to metric")
+	}
+	if vary := rr.Header().Get("Vary"); !strings.Contains(vary, "Accept-Language") {
+		t.Errorf("got Vary %q, want it to include Accept-Language", vary)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleHome(rr, httptest.NewRequest("GET", "/", nil))
+
+	if body := rr.Body.String(); !strings.Contains(body, "20°C") || !strings.Contains(body, "10 m/s") {
+		t.Errorf("weather isn't in metric units:\n%s", body)
+	}
+	if du.GetData().Weather.Temperature != 20 {
+		t.Error("converting for one visitor changed the stored weather")
+	}
+}
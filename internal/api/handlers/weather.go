@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/templates"
+)
+
+type WeatherHandler struct {
+	dataUpdater *templates.DataUpdater
+}
+
+func NewWeatherHandler(dataUpdater *templates.DataUpdater) *WeatherHandler {
+	return &WeatherHandler{
+		dataUpdater: dataUpdater,
+	}
+}
+
+// HandleWeather serves the current weather and forecast shown in the
+// widget, in metric units unless the units query parameter asks for
+// imperial. weather is null until the first successful fetch.
+func (h *WeatherHandler) HandleWeather(w http.ResponseWriter, r *http.Request) {
+	units := models.Metric
+	if param := r.URL.Query().Get("units"); param != "" {
+		var ok bool
+		if units, ok = models.ParseUnits(param); !ok {
+			writeJSONError(w, http.StatusBadRequest, "units must be metric or imperial")
+			return
+		}
+	}
+
+	data := h.dataUpdater.GetData()
+	weather := data.Weather
+	if weather != nil {
+		converted := weather.In(units)
+		weather = &converted
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"weather": weather,
+		"units":   units,
+		"updated": data.LastUpdated,
+	})
+}
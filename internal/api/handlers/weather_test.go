@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/templates"
+)
+
+type weatherSource struct {
+	weather *models.WeatherData
+}
+
+func (s *weatherSource) Name() string            { return "weather" }
+func (s *weatherSource) Key() string             { return models.KeyWeather }
+func (s *weatherSource) Interval() time.Duration { return time.Hour }
+
+func (s *weatherSource) Fetch(context.Context) (any, error) {
+	return s.weather, nil
+}
+
+func TestHandleWeather(t *testing.T) {
+	weather := &models.WeatherData{
+		Location:    "London",
+		Temperature: 12,
+		Forecast: []models.DailyForecast{
+			{Date: time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC), High: 9, Low: 3, Condition: "Rain"},
+		},
+	}
+	du := templates.NewDataUpdater("", nil)
+	du.Register(&weatherSource{weather: weather})
+	du.Update(context.Background())
+
+	rr := httptest.NewRecorder()
+	NewWeatherHandler(du).HandleWeather(rr, httptest.NewRequest("GET", "/api/weather", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusOK)
+	}
+	var resp struct {
+		Weather models.WeatherData `json:"weather"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Weather.Location != "London" {
+		t.Errorf("got location %q, want London", resp.Weather.Location)
+	}
+	if len(resp.Weather.Forecast) != 1 || resp.Weather.Forecast[0].Condition != "Rain" {
+		t.Errorf("got forecast %+v, want one rainy day", resp.Weather.Forecast)
+	}
+}
+
+func TestHandleWeatherUnits(t *testing.T) {
+	du := templates.NewDataUpdater("", nil)
+	du.Register(&weatherSource{weather: &models.WeatherData{
+		Temperature: 20,
+		WindSpeed:   10,
+		Forecast:    []models.DailyForecast{{High: 30, Low: -40}},
+	}})
+	du.Update(context.Background())
+	handler := NewWeatherHandler(du)
+
+	rr := httptest.NewRecorder()
+	handler.HandleWeather(rr, httptest.NewRequest("GET", "/api/weather?units=imperial", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusOK)
+	}
+	var resp struct {
+		Weather models.WeatherData `json:"weather"`
+		Units   models.Units       `json:"units"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Units != models.Imperial {
+		t.Errorf("got units %q, want imperial", resp.Units)
+	}
+	if resp.Weather.Temperature != 68 || resp.Weather.Forecast[0].High != 86 || resp.Weather.Forecast[0].Low != -40 {
+		t.Errorf("temperatures weren't converted to Fahrenheit: %+v", resp.Weather)
+	}
+	if mph := resp.Weather.WindSpeed; mph < 22.36 || mph > 22.37 {
+		t.Errorf("got wind speed %v, want about 22.37 mph", mph)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.HandleWeather(rr, httptest.NewRequest("GET", "/api/weather", nil))
+
+	if !strings.Contains(rr.Body.String(), `"units":"metric"`) || !strings.Contains(rr.Body.String(), `"temperature":20`) {
+		t.Errorf("default isn't metric: %s", rr.Body)
+	}
+}
+
+func TestHandleWeatherRejectsUnknownUnits(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewWeatherHandler(templates.NewDataUpdater("", nil)).HandleWeather(rr, httptest.NewRequest("GET", "/api/weather?units=kelvin", nil))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
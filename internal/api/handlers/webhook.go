@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/templates"
+)
+
+// webhookEvent holds the parts of GitHub webhook payloads we care about.
+// Push, release, create and star events all share this shape.
+type webhookEvent struct {
+	Action     string `json:"action"`
+	Ref        string `json:"ref"`
+	RefType    string `json:"ref_type"`
+	Commits    []any  `json:"commits"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Release struct {
+		TagName string `json:"tag_name"`
+	} `json:"release"`
+}
+
+// webhookRefreshes maps each handled event type to the data it changes.
+var webhookRefreshes = map[string][]string{
+	"push":    {models.KeyRepositories, models.KeyActivity, models.KeyContributions},
+	"release": {models.KeyRepositories, models.KeyActivity},
+	"create":  {models.KeyRepositories, models.KeyActivity},
+	"star":    {models.KeyRepositories, models.KeyStats},
+}
+
+type WebhookHandler struct {
+	secret      []byte
+	dataUpdater *templates.DataUpdater
+}
+
+func NewWebhookHandler(secret string, dataUpdater *templates.DataUpdater) *WebhookHandler {
+	h := &WebhookHandler{dataUpdater: dataUpdater}
+	if secret != "" {
+		h.secret = []byte(secret)
+	}
+	return h
+}
+
+type webhookResponse struct {
+	Event      string   `json:"event"`
+	Refreshing []string `json:"refreshing,omitempty"`
+}
+
+// HandleGithubWebhook refreshes the GitHub data as soon as GitHub tells us
+// something changed, rather than waiting for the next scheduled refresh.
+func (h *WebhookHandler) HandleGithubWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.secret == nil {
+		writeJSONError(w, http.StatusForbidden, "webhooks disabled: no secret configured")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	if !validSignature(h.secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		writeJSONError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	eventType := r.Header.Get("X-GitHub-Event")
+	if eventType == "ping" {
+		writeJSON(w, http.StatusOK, webhookResponse{Event: eventType})
+		return
+	}
+
+	keys, ok := webhookRefreshes[eventType]
+	if !ok {
+		writeJSON(w, http.StatusAccepted, webhookResponse{Event: eventType})
+		return
+	}
+
+	var event webhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid payload: "+err.Error())
+		return
+	}
+
+	logging.Info("GitHub webhook: %s", describeWebhookEvent(eventType, event))
+	h.dataUpdater.Refresh(keys...)
+
+	writeJSON(w, http.StatusAccepted, webhookResponse{Event: eventType, Refreshing: keys})
+}
+
+func describeWebhookEvent(eventType string, e webhookEvent) string {
+	repo := e.Repository.FullName
+	switch eventType {
+	case "push":
+		return fmt.Sprintf("%d commit(s) pushed to %s (%s)", len(e.Commits), repo, e.Ref)
+	case "release":
+		return fmt.Sprintf("release %s %s in %s", e.Release.TagName, e.Action, repo)
+	case "create":
+		return fmt.Sprintf("%s %s created in %s", e.RefType, e.Ref, repo)
+	case "star":
+		return fmt.Sprintf("star %s on %s", e.Action, repo)
+	default:
+		return eventType + " on " + repo
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/templates"
+)
+
+const testWebhookSecret = "It's a Secret to Everybody"
+
+type countingSource struct {
+	key   string
+	calls atomic.Int32
+}
+
+func (s *countingSource) Name() string            { return s.key }
+func (s *countingSource) Key() string             { return s.key }
+func (s *countingSource) Interval() time.Duration { return time.Hour }
+
+func (s *countingSource) Fetch(context.Context) (any, error) {
+	s.calls.Add(1)
+	return nil, nil
+}
+
+func newWebhookRequest(t *testing.T, event, secret string) *http.Request {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", "webhooks", event+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	req.Header.Set("X-Hub-Signature-256", sign(secret, string(body)))
+	return req
+}
+
+func TestHandleGithubWebhook(t *testing.T) {
+	tests := []struct {
+		event         string
+		wantStatus    int
+		wantRefreshed []string
+	}{
+		{"push", http.StatusAccepted, []string{models.KeyRepositories, models.KeyActivity, models.KeyContributions}},
+		{"release", http.StatusAccepted, []string{models.KeyRepositories, models.KeyActivity}},
+		{"create", http.StatusAccepted, []string{models.KeyRepositories, models.KeyActivity}},
+		{"star", http.StatusAccepted, []string{models.KeyRepositories, models.KeyStats}},
+		{"ping", http.StatusOK, nil},
+		{"issues", http.StatusAccepted, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.event, func(t *testing.T) {
+			sources := map[string]*countingSource{
+				models.KeyRepositories:  {key: models.KeyRepositories},
+				models.KeyActivity:      {key: models.KeyActivity},
+				models.KeyStats:         {key: models.KeyStats},
+				models.KeyContributions: {key: models.KeyContributions},
+				models.KeyWeather:       {key: models.KeyWeather},
+			}
+			du := templates.NewDataUpdater("", nil)
+			for _, src := range sources {
+				du.Register(src)
+			}
+			du.Update(context.Background())
+
+			handler := NewWebhookHandler(testWebhookSecret, du)
+			rr := httptest.NewRecorder()
+			handler.HandleGithubWebhook(rr, newWebhookRequest(t, tc.event, testWebhookSecret))
+
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", rr.Code, tc.wantStatus, rr.Body)
+			}
+
+			if err := du.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			for key, src := range sources {
+				want := int32(1)
+				for _, refreshed := range tc.wantRefreshed {
+					if refreshed == key {
+						want = 2
+					}
+				}
+				// Shutdown may cancel the refresh before it fetches, but it
+				// must never touch sources the event didn't affect.
+				if got := src.calls.Load(); got > want || (want == 1 && got != 1) {
+					t.Errorf("%s: fetched %d times, want %d", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGithubWebhookRefreshesInBackground(t *testing.T) {
+	repos := &countingSource{key: models.KeyRepositories}
+	du := templates.NewDataUpdater("", nil)
+	du.Register(repos)
+	du.Update(context.Background())
+
+	handler := NewWebhookHandler(testWebhookSecret, du)
+	handler.HandleGithubWebhook(httptest.NewRecorder(), newWebhookRequest(t, "star", testWebhookSecret))
+
+	deadline := time.Now().Add(time.Second)
+	for repos.calls.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("repositories were not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	du.Shutdown(context.Background())
+}
+
+func TestHandleGithubWebhookRejectsBadSignature(t *testing.T) {
+	du := templates.NewDataUpdater("", nil)
+	handler := NewWebhookHandler(testWebhookSecret, du)
+
+	rr := httptest.NewRecorder()
+	handler.HandleGithubWebhook(rr, newWebhookRequest(t, "push", "wrong secret"))
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestHandleGithubWebhookDisabledWithoutSecret(t *testing.T) {
+	handler := NewWebhookHandler("", templates.NewDataUpdater("", nil))
+
+	rr := httptest.NewRecorder()
+	handler.HandleGithubWebhook(rr, newWebhookRequest(t, "push", ""))
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusForbidden)
+	}
+}
@@ -7,28 +7,98 @@ import (
 
 	"github.com/josephburgess/glogger"
 	"github.com/josephburgess/joeburgess.dev/internal/api/handlers"
+	"github.com/josephburgess/joeburgess.dev/internal/config"
 	"github.com/josephburgess/joeburgess.dev/internal/logging"
 	"github.com/josephburgess/joeburgess.dev/internal/templates"
 )
 
-func Setup(tmplRenderer *templates.Renderer, dataUpdater *templates.DataUpdater) *http.Server {
+// Options controls how the server listens and what it serves.
+type Options struct {
+	Addr         string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	StaticDir    string
+	Blog         BlogOptions
+	Auth         AuthOptions
+}
+
+// AuthOptions protects endpoints that change server state.
+type AuthOptions struct {
+	Token               string
+	HMACSecret          string
+	GithubWebhookSecret string
+	UpdateRate          time.Duration
+	UpdateBurst         int
+}
+
+// BlogOptions is passed through to glogger.
+type BlogOptions struct {
+	ContentDir  string
+	URLPrefix   string
+	Theme       string
+	Title       string
+	Description string
+	BaseURL     string
+}
+
+// NewOptions derives server options from the loaded configuration.
+func NewOptions(cfg *config.Config) Options {
+	return Options{
+		Addr:         cfg.Server.Address,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		StaticDir:    cfg.Server.StaticDir,
+		Blog: BlogOptions{
+			ContentDir:  cfg.Blog.ContentDir,
+			URLPrefix:   cfg.Blog.URLPrefix,
+			Theme:       cfg.Blog.Theme,
+			Title:       cfg.Blog.Title,
+			Description: cfg.Blog.Description,
+			BaseURL:     cfg.Server.BaseURL,
+		},
+		Auth: AuthOptions{
+			Token:               cfg.API.Token,
+			HMACSecret:          cfg.API.HMACSecret,
+			GithubWebhookSecret: cfg.GitHub.WebhookSecret,
+			UpdateRate:          cfg.API.UpdateRate,
+			UpdateBurst:         cfg.API.UpdateBurst,
+		},
+	}
+}
+
+func Setup(opts Options, tmplRenderer *templates.Renderer, dataUpdater *templates.DataUpdater, locations handlers.LocationStore) *http.Server {
 	mux := http.NewServeMux()
 
 	homeHandler := handlers.NewHomeHandler(tmplRenderer, dataUpdater)
 	githubHandler := handlers.NewGithubHandler(dataUpdater)
+	weatherHandler := handlers.NewWeatherHandler(dataUpdater)
+	webhookHandler := handlers.NewWebhookHandler(opts.Auth.GithubWebhookSecret, dataUpdater)
+	locationHandler := handlers.NewLocationHandler(locations, dataUpdater)
+
+	auth := handlers.NewAuthenticator(opts.Auth.Token, opts.Auth.HMACSecret)
+	if !auth.Enabled() {
+		logging.Warn("No API token or HMAC secret configured, update endpoints are disabled")
+	}
+	updateLimiter := handlers.NewRateLimiter(opts.Auth.UpdateRate, opts.Auth.UpdateBurst)
 
 	mux.HandleFunc("GET /{$}", homeHandler.HandleHome)
-	mux.HandleFunc("POST /update-data", homeHandler.HandleUpdateData)
+	mux.HandleFunc("POST /update-data", auth.Require(updateLimiter.Limit(homeHandler.HandleUpdateData)))
+	mux.HandleFunc("GET /update-data/{id}", auth.Require(homeHandler.HandleUpdateStatus))
 	mux.HandleFunc("GET /api/github-data", githubHandler.HandleGithubData)
+	mux.HandleFunc("GET /api/weather", weatherHandler.HandleWeather)
+	mux.HandleFunc("POST /api/location", auth.RequireBasic(locationHandler.HandleLocationUpdate))
+	mux.HandleFunc("POST /webhooks/github", webhookHandler.HandleGithubWebhook)
 	mux.HandleFunc("/", homeHandler.HandleNotFound)
 
 	blog, err := glogger.New(glogger.Config{
-		ContentDir:  "content/posts",
-		URLPrefix:   "/blog",
-		Theme:       glogger.ThemeRosePine,
-		Title:       "joeburgess.blog",
-		Description: "Joe Burgess personal blog",
-		BaseURL:     "https://joeburgess.dev",
+		ContentDir:  opts.Blog.ContentDir,
+		URLPrefix:   opts.Blog.URLPrefix,
+		Theme:       opts.Blog.Theme,
+		Title:       opts.Blog.Title,
+		Description: opts.Blog.Description,
+		BaseURL:     opts.Blog.BaseURL,
 	})
 	if err != nil {
 		logging.Error("Failed to create blog", err)
@@ -36,16 +106,16 @@ func Setup(tmplRenderer *templates.Renderer, dataUpdater *templates.DataUpdater)
 		blog.Mount(mux)
 	}
 
-	fs := http.FileServer(http.Dir("static"))
+	fs := http.FileServer(http.Dir(opts.StaticDir))
 	mux.Handle("/static/", http.StripPrefix("/static/", fs))
 
 	handler := logging.Middleware(mux)
 
 	return &http.Server{
-		Addr:         ":8081",
+		Addr:         opts.Addr,
 		Handler:      handler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  opts.ReadTimeout,
+		WriteTimeout: opts.WriteTimeout,
+		IdleTimeout:  opts.IdleTimeout,
 	}
 }
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/config"
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOptions(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address:      ":9000",
+			ReadTimeout:  time.Second,
+			WriteTimeout: 2 * time.Second,
+			IdleTimeout:  3 * time.Second,
+			StaticDir:    "public",
+			BaseURL:      "https://example.com",
+		},
+		Blog: config.BlogConfig{
+			ContentDir:  "posts",
+			URLPrefix:   "/writing",
+			Theme:       "dark",
+			Title:       "example.blog",
+			Description: "An example blog",
+		},
+	}
+
+	opts := NewOptions(cfg)
+
+	assert.Equal(t, ":9000", opts.Addr)
+	assert.Equal(t, time.Second, opts.ReadTimeout)
+	assert.Equal(t, 2*time.Second, opts.WriteTimeout)
+	assert.Equal(t, 3*time.Second, opts.IdleTimeout)
+	assert.Equal(t, "public", opts.StaticDir)
+	assert.Equal(t, BlogOptions{
+		ContentDir:  "posts",
+		URLPrefix:   "/writing",
+		Theme:       "dark",
+		Title:       "example.blog",
+		Description: "An example blog",
+		BaseURL:     "https://example.com",
+	}, opts.Blog)
+}
+
+func TestSetupUsesOptions(t *testing.T) {
+	logging.NewLogger()
+
+	staticDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(staticDir, "hello.txt"), []byte("hello"), 0o644))
+
+	contentDir := t.TempDir()
+	post := "---\ntitle: Test Post\ndate: 2025-01-01\n---\n\nHello from a test.\n"
+	require.NoError(t, os.WriteFile(filepath.Join(contentDir, "test-post.md"), []byte(post), 0o644))
+
+	srv := Setup(Options{
+		Addr:         "127.0.0.1:9999",
+		ReadTimeout:  time.Second,
+		WriteTimeout: 2 * time.Second,
+		IdleTimeout:  3 * time.Second,
+		StaticDir:    staticDir,
+		Blog: BlogOptions{
+			ContentDir: contentDir,
+			URLPrefix:  "/writing",
+			Theme:      "rosepine",
+			Title:      "test.blog",
+		},
+	}, nil, nil, nil)
+
+	assert.Equal(t, "127.0.0.1:9999", srv.Addr)
+	assert.Equal(t, time.Second, srv.ReadTimeout)
+	assert.Equal(t, 2*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 3*time.Second, srv.IdleTimeout)
+
+	rr := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/static/hello.txt", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "hello", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/writing/", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "Test Post")
+}
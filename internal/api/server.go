@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+)
+
+// Run listens on srv.Addr and serves until ctx is cancelled. See Serve.
+func Run(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration, stop ...func(context.Context)) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		for _, fn := range stop {
+			fn(shutdownCtx)
+		}
+		return err
+	}
+	return Serve(ctx, srv, ln, shutdownTimeout, stop...)
+}
+
+// Serve serves srv on ln until ctx is cancelled, then stops accepting new
+// connections and waits up to shutdownTimeout for in-flight requests to
+// complete. Each stop function, such as DataUpdater.Shutdown, is called as
+// soon as ctx is cancelled and runs alongside the drain with the same
+// deadline, so the whole shutdown fits in shutdownTimeout. It returns nil on
+// a clean shutdown.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, shutdownTimeout time.Duration, stop ...func(context.Context)) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var stopped sync.WaitGroup
+	for _, fn := range stop {
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			fn(shutdownCtx)
+		}()
+	}
+	defer stopped.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	logging.Info("Shutting down server, waiting up to %s for requests to complete", shutdownTimeout)
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
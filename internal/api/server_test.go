@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.Write([]byte("done"))
+		}),
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- Serve(ctx, srv, ln, 5*time.Second)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	respCh := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			respCh <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		respCh <- result{body: string(b), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-serveErr:
+		t.Fatalf("Serve returned before in-flight request completed: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	res := <-respCh
+	assert.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+	assert.NoError(t, <-serveErr)
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		}),
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- Serve(ctx, srv, ln, 50*time.Millisecond)
+	}()
+
+	go http.Get("http://" + ln.Addr().String())
+
+	<-started
+	cancel()
+
+	assert.ErrorIs(t, <-serveErr, context.DeadlineExceeded)
+}
+
+func TestServeStopsAlongsideDrain(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		}),
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	stopping := make(chan time.Time, 1)
+	stop := func(ctx context.Context) {
+		deadline, _ := ctx.Deadline()
+		stopping <- deadline
+		<-ctx.Done()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- Serve(ctx, srv, ln, 200*time.Millisecond, stop)
+	}()
+
+	go http.Get("http://" + ln.Addr().String())
+
+	<-started
+	start := time.Now()
+	cancel()
+
+	select {
+	case deadline := <-stopping:
+		assert.WithinDuration(t, start.Add(200*time.Millisecond), deadline, 100*time.Millisecond, "shares the drain's deadline")
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("stop wasn't called while requests were still draining")
+	}
+
+	assert.ErrorIs(t, <-serveErr, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 400*time.Millisecond, "the drain and stop share one timeout")
+}
+
+func TestServeReturnsListenerErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln.Close()
+
+	err = Serve(context.Background(), &http.Server{}, ln, time.Second)
+	assert.Error(t, err)
+}
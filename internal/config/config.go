@@ -1,39 +1,570 @@
+// Package config loads site configuration from defaults, an optional YAML
+// file and environment variables, in that order of precedence.
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/glogger"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+	"github.com/josephburgess/joeburgess.dev/internal/services/github"
+	"github.com/josephburgess/joeburgess.dev/internal/services/weather"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	ServerAddress   string
-	GithubUsername  string
-	WeatherLocation string
-	WeatherAPIKey   string
-	ProfileImage    string
-	GithubURL       string
-	LinkedInURL     string
-	BreezeURL       string
-	Email           string
-}
-
-func Load() *Config {
+	Server  ServerConfig  `yaml:"server"`
+	Blog    BlogConfig    `yaml:"blog"`
+	Profile ProfileConfig `yaml:"profile"`
+	GitHub  GitHubConfig  `yaml:"github"`
+	GitLab  ForgeConfig   `yaml:"gitlab"`
+	Gitea   ForgeConfig   `yaml:"gitea"`
+	Weather WeatherConfig `yaml:"weather"`
+	API     APIConfig     `yaml:"api"`
+}
+
+type ServerConfig struct {
+	Address         string        `yaml:"address"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	StaticDir       string        `yaml:"static_dir"`
+	DataDir         string        `yaml:"data_dir"`
+	BaseURL         string        `yaml:"base_url"`
+}
+
+type BlogConfig struct {
+	ContentDir  string `yaml:"content_dir"`
+	URLPrefix   string `yaml:"url_prefix"`
+	Theme       string `yaml:"theme"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+}
+
+type ProfileConfig struct {
+	Image string        `yaml:"image"`
+	Links []models.Link `yaml:"links"`
+}
+
+type GitHubConfig struct {
+	Username        string        `yaml:"username"`
+	Token           string        `yaml:"token"`
+	APIURL          string        `yaml:"api_url"`
+	WebURL          string        `yaml:"web_url"`
+	WebhookSecret   string        `yaml:"webhook_secret"`
+	HiddenEvents    []string      `yaml:"hidden_events"`
+	Repos           RepoConfig    `yaml:"repos"`
+	ReposRefresh    time.Duration `yaml:"repos_refresh"`
+	ActivityRefresh time.Duration `yaml:"activity_refresh"`
+	StatsRefresh    time.Duration `yaml:"stats_refresh"`
+	// ContributionsRefresh only applies with a token, which the
+	// contribution calendar needs.
+	ContributionsRefresh time.Duration `yaml:"contributions_refresh"`
+}
+
+// RepoConfig picks which repositories the homepage lists, from every forge.
+// Include and Exclude are glob patterns matched against repository names.
+type RepoConfig struct {
+	// List is recent, pinned or both. Pinned repositories are GitHub's and
+	// need a token; other forges always list recent ones.
+	List          string   `yaml:"list"`
+	Include       []string `yaml:"include"`
+	Exclude       []string `yaml:"exclude"`
+	SkipForks     bool     `yaml:"skip_forks"`
+	SkipArchived  bool     `yaml:"skip_archived"`
+	SkipTemplates bool     `yaml:"skip_templates"`
+	MinStars      int      `yaml:"min_stars"`
+	Sort          string   `yaml:"sort"`
+	Count         int      `yaml:"count"`
+}
+
+// ForgeConfig enables a forge alongside GitHub, such as GitLab or a Gitea or
+// Forgejo instance like Codeberg, when Username is set. Its repositories are
+// picked by github.repos and its activity filtered by github.hidden_events,
+// so every forge follows the same rules.
+type ForgeConfig struct {
+	Username        string        `yaml:"username"`
+	Token           string        `yaml:"token"`
+	URL             string        `yaml:"url"`
+	ReposRefresh    time.Duration `yaml:"repos_refresh"`
+	ActivityRefresh time.Duration `yaml:"activity_refresh"`
+}
+
+// WeatherConfig picks where the weather widget's data comes from. Provider
+// is one of weather.Providers; URL and GeocodingURL default to the
+// provider's public API and BreezeURL is where breeze's credit links to.
+type WeatherConfig struct {
+	Provider     string        `yaml:"provider"`
+	Location     string        `yaml:"location"`
+	APIKey       string        `yaml:"api_key"`
+	URL          string        `yaml:"url"`
+	GeocodingURL string        `yaml:"geocoding_url"`
+	BreezeURL    string        `yaml:"breeze_url"`
+	Refresh      time.Duration `yaml:"refresh"`
+}
+
+// APIConfig holds the credentials for endpoints that change server state,
+// such as POST /update-data. Either a bearer token or an HMAC secret for
+// signed, timestamped requests will do.
+type APIConfig struct {
+	Token       string        `yaml:"token"`
+	HMACSecret  string        `yaml:"hmac_secret"`
+	UpdateRate  time.Duration `yaml:"update_rate"`
+	UpdateBurst int           `yaml:"update_burst"`
+}
+
+// Default returns the configuration used for joeburgess.dev when nothing
+// else is provided.
+func Default() *Config {
 	return &Config{
-		ServerAddress:   getEnv("SERVER_ADDRESS", ":8081"),
-		GithubUsername:  getEnv("GITHUB_USERNAME", "josephburgess"),
-		WeatherLocation: getEnv("WEATHER_LOCATION", "London, GB"),
-		WeatherAPIKey:   os.Getenv("BREEZE_API_KEY"),
-		ProfileImage:    "/static/images/profile.png",
-		GithubURL:       "https://github.com/josephburgess",
-		LinkedInURL:     "https://linkedin.com/in/josephburgessmba",
-		BreezeURL:       "https://github.com/josephburgess/breeze",
-		Email:           "joe@joeburgess.dev",
+		Server: ServerConfig{
+			Address:         ":8081",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 8 * time.Second, // docker sends SIGKILL after 10s
+			StaticDir:       "static",
+			DataDir:         "data",
+			BaseURL:         "https://joeburgess.dev",
+		},
+		Blog: BlogConfig{
+			ContentDir:  "content/posts",
+			URLPrefix:   "/blog",
+			Theme:       glogger.ThemeRosePine,
+			Title:       "joeburgess.blog",
+			Description: "Joe Burgess personal blog",
+		},
+		Profile: ProfileConfig{
+			Image: "/static/images/profile.png",
+			Links: []models.Link{
+				{Name: "GitHub", URL: "https://github.com/josephburgess", Icon: "icon-github", Rel: []string{"me"}},
+				{Name: "LinkedIn", URL: "https://linkedin.com/in/josephburgessmba", Icon: "icon-linkedin", Rel: []string{"me"}},
+				{Name: "Email", URL: "mailto:joe@joeburgess.dev", Icon: "icon-email"},
+			},
+		},
+		GitHub: GitHubConfig{
+			Username: "josephburgess",
+			APIURL:   "https://api.github.com",
+			WebURL:   "https://github.com",
+			Repos: RepoConfig{
+				List:         github.ListRecent,
+				Exclude:      []string{"homebrew-formulae", "excalith-start-page"},
+				SkipForks:    true,
+				SkipArchived: true,
+				Sort:         forge.SortUpdated,
+				Count:        6,
+			},
+			ReposRefresh:         time.Hour,
+			ActivityRefresh:      15 * time.Minute,
+			StatsRefresh:         6 * time.Hour,
+			ContributionsRefresh: time.Hour,
+		},
+		GitLab: ForgeConfig{
+			URL:             "https://gitlab.com",
+			ReposRefresh:    time.Hour,
+			ActivityRefresh: 15 * time.Minute,
+		},
+		Gitea: ForgeConfig{
+			URL:             "https://codeberg.org",
+			ReposRefresh:    time.Hour,
+			ActivityRefresh: 15 * time.Minute,
+		},
+		Weather: WeatherConfig{
+			Provider:  weather.ProviderBreeze,
+			Location:  "London, GB",
+			BreezeURL: "https://github.com/josephburgess/breeze",
+			Refresh:   10 * time.Minute,
+		},
+		API: APIConfig{
+			UpdateRate:  time.Minute,
+			UpdateBurst: 3,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path (or
+// $CONFIG_FILE when path is empty) and environment variable overrides, then
+// validates the result. Every problem found is reported in the returned error.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []FieldError
+	problems = append(problems, cfg.applyEnv()...)
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q, expected .yaml or .yml", path, ext)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) applyEnv() []FieldError {
+	e := envReader{}
+
+	e.string(&c.Server.Address, "SERVER_ADDRESS")
+	e.duration(&c.Server.ReadTimeout, "READ_TIMEOUT")
+	e.duration(&c.Server.WriteTimeout, "WRITE_TIMEOUT")
+	e.duration(&c.Server.IdleTimeout, "IDLE_TIMEOUT")
+	e.duration(&c.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT")
+	e.string(&c.Server.StaticDir, "STATIC_DIR")
+	e.string(&c.Server.DataDir, "DATA_DIR")
+	e.string(&c.Server.BaseURL, "BASE_URL")
+
+	e.string(&c.Blog.ContentDir, "BLOG_CONTENT_DIR")
+	e.string(&c.Blog.URLPrefix, "BLOG_URL_PREFIX")
+	e.string(&c.Blog.Theme, "BLOG_THEME")
+	e.string(&c.Blog.Title, "BLOG_TITLE")
+	e.string(&c.Blog.Description, "BLOG_DESCRIPTION")
+
+	e.string(&c.GitHub.Username, "GITHUB_USERNAME")
+	e.string(&c.GitHub.Token, "GITHUB_TOKEN")
+	e.string(&c.GitHub.APIURL, "GITHUB_API_URL")
+	e.string(&c.GitHub.WebURL, "GITHUB_WEB_URL")
+	e.string(&c.GitHub.WebhookSecret, "GITHUB_WEBHOOK_SECRET")
+	e.list(&c.GitHub.HiddenEvents, "GITHUB_HIDDEN_EVENTS")
+	e.string(&c.GitHub.Repos.List, "GITHUB_REPOS_LIST")
+	e.list(&c.GitHub.Repos.Include, "GITHUB_REPOS_INCLUDE")
+	e.list(&c.GitHub.Repos.Exclude, "GITHUB_REPOS_EXCLUDE")
+	e.string(&c.GitHub.Repos.Sort, "GITHUB_REPOS_SORT")
+	e.int(&c.GitHub.Repos.Count, "GITHUB_REPOS_COUNT")
+	e.duration(&c.GitHub.ReposRefresh, "GITHUB_REPOS_REFRESH")
+	e.duration(&c.GitHub.ActivityRefresh, "GITHUB_ACTIVITY_REFRESH")
+	e.duration(&c.GitHub.StatsRefresh, "GITHUB_STATS_REFRESH")
+	e.duration(&c.GitHub.ContributionsRefresh, "GITHUB_CONTRIBUTIONS_REFRESH")
+
+	e.forge(&c.GitLab, "GITLAB")
+	e.forge(&c.Gitea, "GITEA")
+
+	e.string(&c.Weather.Provider, "WEATHER_PROVIDER")
+	e.string(&c.Weather.Location, "WEATHER_LOCATION")
+	// The BREEZE_ names predate the other providers and still work, but only
+	// for breeze: docker-compose always sets BREEZE_API_URL, which would
+	// otherwise send every provider's requests to the breeze container.
+	if c.Weather.Provider == weather.ProviderBreeze {
+		e.string(&c.Weather.APIKey, "BREEZE_API_KEY")
+		e.string(&c.Weather.URL, "BREEZE_API_URL")
+	}
+	e.string(&c.Weather.APIKey, "WEATHER_API_KEY")
+	e.string(&c.Weather.URL, "WEATHER_API_URL")
+	e.string(&c.Weather.GeocodingURL, "WEATHER_GEOCODING_URL")
+	e.duration(&c.Weather.Refresh, "WEATHER_REFRESH")
+
+	e.string(&c.API.Token, "API_TOKEN")
+	e.string(&c.API.HMACSecret, "API_HMAC_SECRET")
+
+	return e.problems
+}
+
+func (c *Config) validate() []FieldError {
+	v := validator{}
+
+	v.required("server.address", c.Server.Address)
+	v.positive("server.read_timeout", c.Server.ReadTimeout)
+	v.positive("server.write_timeout", c.Server.WriteTimeout)
+	v.positive("server.idle_timeout", c.Server.IdleTimeout)
+	v.positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	v.required("server.static_dir", c.Server.StaticDir)
+	v.required("server.data_dir", c.Server.DataDir)
+	v.absoluteURL("server.base_url", c.Server.BaseURL)
+
+	v.required("blog.content_dir", c.Blog.ContentDir)
+	if !strings.HasPrefix(c.Blog.URLPrefix, "/") {
+		v.add("blog.url_prefix", "must start with /, got %q", c.Blog.URLPrefix)
+	}
+	themes := []string{glogger.ThemeDefault, glogger.ThemeDark, glogger.ThemeLight, glogger.ThemeRosePine}
+	if !slices.Contains(themes, c.Blog.Theme) {
+		v.add("blog.theme", "must be one of %s, got %q", strings.Join(themes, ", "), c.Blog.Theme)
 	}
+
+	v.required("profile.image", c.Profile.Image)
+	icons, iconsErr := loadIconIDs(filepath.Join(c.Server.StaticDir, "icons", "icons.svg"))
+	for i, link := range c.Profile.Links {
+		field := fmt.Sprintf("profile.links[%d]", i)
+		v.required(field+".name", link.Name)
+		v.linkURL(field+".url", link.URL)
+		switch {
+		case link.Icon == "":
+			v.add(field+".icon", "is required")
+		case iconsErr == nil && !icons[link.Icon]:
+			v.add(field+".icon", "no symbol %q in icons.svg", link.Icon)
+		}
+	}
+
+	v.required("github.username", c.GitHub.Username)
+	v.absoluteURL("github.api_url", c.GitHub.APIURL)
+	v.absoluteURL("github.web_url", c.GitHub.WebURL)
+	v.secret("github.webhook_secret", c.GitHub.WebhookSecret)
+	for i, event := range c.GitHub.HiddenEvents {
+		if !strings.HasSuffix(event, "Event") {
+			v.add(fmt.Sprintf("github.hidden_events[%d]", i), "must be an event type such as WatchEvent, got %q", event)
+		}
+	}
+	switch {
+	case !slices.Contains(github.RepoLists, c.GitHub.Repos.List):
+		v.add("github.repos.list", "must be one of %s, got %q", strings.Join(github.RepoLists, ", "), c.GitHub.Repos.List)
+	case c.GitHub.Repos.List != github.ListRecent && c.GitHub.Token == "":
+		v.add("github.repos.list", "%s repositories need github.token", c.GitHub.Repos.List)
+	}
+	v.patterns("github.repos.include", c.GitHub.Repos.Include)
+	v.patterns("github.repos.exclude", c.GitHub.Repos.Exclude)
+	if c.GitHub.Repos.MinStars < 0 {
+		v.add("github.repos.min_stars", "must not be negative, got %d", c.GitHub.Repos.MinStars)
+	}
+	if !slices.Contains(forge.SortOrders, c.GitHub.Repos.Sort) {
+		v.add("github.repos.sort", "must be one of %s, got %q", strings.Join(forge.SortOrders, ", "), c.GitHub.Repos.Sort)
+	}
+	if c.GitHub.Repos.Count < 1 {
+		v.add("github.repos.count", "must be at least 1, got %d", c.GitHub.Repos.Count)
+	}
+	v.positive("github.repos_refresh", c.GitHub.ReposRefresh)
+	v.positive("github.activity_refresh", c.GitHub.ActivityRefresh)
+	v.positive("github.stats_refresh", c.GitHub.StatsRefresh)
+	v.positive("github.contributions_refresh", c.GitHub.ContributionsRefresh)
+
+	v.forge("gitlab", c.GitLab)
+	v.forge("gitea", c.Gitea)
+
+	if !slices.Contains(weather.Providers, c.Weather.Provider) {
+		v.add("weather.provider", "must be one of %s, got %q", strings.Join(weather.Providers, ", "), c.Weather.Provider)
+	}
+	v.optionalURL("weather.url", c.Weather.URL)
+	v.optionalURL("weather.geocoding_url", c.Weather.GeocodingURL)
+	v.optionalURL("weather.breeze_url", c.Weather.BreezeURL)
+	v.positive("weather.refresh", c.Weather.Refresh)
+
+	v.secret("api.token", c.API.Token)
+	v.secret("api.hmac_secret", c.API.HMACSecret)
+	v.positive("api.update_rate", c.API.UpdateRate)
+	if c.API.UpdateBurst < 1 {
+		v.add("api.update_burst", "must be at least 1, got %d", c.API.UpdateBurst)
+	}
+
+	return v.problems
+}
+
+// FieldError describes a single invalid configuration value.
+type FieldError struct {
+	Field   string
+	Message string
 }
 
-func getEnv(key, defaultValue string) string {
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError collects every problem found while loading the
+// configuration so they can all be fixed in one go.
+type ValidationError struct {
+	Problems []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid configuration:")
+	for _, p := range e.Problems {
+		sb.WriteString("\n  ")
+		sb.WriteString(p.Error())
+	}
+	return sb.String()
+}
+
+type envReader struct {
+	problems []FieldError
+}
+
+func (e *envReader) string(dst *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*dst = value
+	}
+}
+
+// list reads a comma-separated list, replacing any configured value.
+func (e *envReader) list(dst *[]string, key string) {
 	value := os.Getenv(key)
 	if value == "" {
-		return defaultValue
+		return
+	}
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+func (e *envReader) int(dst *int, key string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.problems = append(e.problems, FieldError{Field: key, Message: fmt.Sprintf("invalid integer %q", value)})
+		return
+	}
+	*dst = n
+}
+
+// forge reads a ForgeConfig from PREFIX_USERNAME, PREFIX_TOKEN and so on.
+func (e *envReader) forge(dst *ForgeConfig, prefix string) {
+	e.string(&dst.Username, prefix+"_USERNAME")
+	e.string(&dst.Token, prefix+"_TOKEN")
+	e.string(&dst.URL, prefix+"_URL")
+	e.duration(&dst.ReposRefresh, prefix+"_REPOS_REFRESH")
+	e.duration(&dst.ActivityRefresh, prefix+"_ACTIVITY_REFRESH")
+}
+
+func (e *envReader) duration(dst *time.Duration, key string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.problems = append(e.problems, FieldError{Field: key, Message: fmt.Sprintf("invalid duration %q", value)})
+		return
+	}
+	*dst = d
+}
+
+type validator struct {
+	problems []FieldError
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.problems = append(v.problems, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) positive(field string, d time.Duration) {
+	if d <= 0 {
+		v.add(field, "must be a positive duration, got %s", d)
+	}
+}
+
+// minSecretLength keeps API credentials out of brute-force range.
+const minSecretLength = 16
+
+func (v *validator) secret(field, value string) {
+	if value != "" && len(value) < minSecretLength {
+		v.add(field, "must be at least %d characters", minSecretLength)
+	}
+}
+
+func (v *validator) absoluteURL(field, value string) {
+	u, err := url.Parse(value)
+	if err != nil {
+		v.add(field, "invalid URL: %v", err)
+		return
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, "must be an absolute http(s) URL, got %q", value)
+	}
+}
+
+func (v *validator) optionalURL(field, value string) {
+	if value != "" {
+		v.absoluteURL(field, value)
+	}
+}
+
+func (v *validator) email(field, value string) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		v.add(field, "invalid email address %q", value)
+	}
+}
+
+// linkURL accepts absolute http(s) URLs and mailto: links.
+func (v *validator) linkURL(field, value string) {
+	if address, ok := strings.CutPrefix(value, "mailto:"); ok {
+		v.email(field, address)
+		return
+	}
+	v.absoluteURL(field, value)
+}
+
+// forge checks a forge's settings, if it's enabled.
+func (v *validator) forge(field string, f ForgeConfig) {
+	if f.Username == "" {
+		return
+	}
+	v.absoluteURL(field+".url", f.URL)
+	v.positive(field+".repos_refresh", f.ReposRefresh)
+	v.positive(field+".activity_refresh", f.ActivityRefresh)
+}
+
+// patterns checks glob patterns in the syntax of path.Match.
+func (v *validator) patterns(field string, patterns []string) {
+	for i, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			v.add(fmt.Sprintf("%s[%d]", field, i), "invalid pattern %q", pattern)
+		}
+	}
+}
+
+var symbolID = regexp.MustCompile(`<symbol[^>]*\sid="([^"]+)"`)
+
+// loadIconIDs returns the symbol ids defined in the icon sprite at path.
+// Link icons are only checked against the sprite when it can be read.
+func loadIconIDs(path string) (map[string]bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for _, m := range symbolID.FindAllSubmatch(b, -1) {
+		ids[string(m[1])] = true
 	}
-	return value
+	return ids, nil
 }
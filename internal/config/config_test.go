@@ -0,0 +1,286 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeConfig(t, "site.yaml", `
+server:
+  address: ":9090"
+  read_timeout: 5s
+profile:
+  links:
+    - name: Mastodon
+      url: https://hachyderm.io/@someone
+      icon: icon-mastodon
+      rel: [me]
+    - name: Email
+      url: mailto:someone@example.com
+      icon: icon-email
+github:
+  username: someone
+`)
+
+	cfg, err := Load(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, ":9090", cfg.Server.Address)
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 15*time.Second, cfg.Server.WriteTimeout, "unset values keep their defaults")
+	assert.Equal(t, []models.Link{
+		{Name: "Mastodon", URL: "https://hachyderm.io/@someone", Icon: "icon-mastodon", Rel: []string{"me"}},
+		{Name: "Email", URL: "mailto:someone@example.com", Icon: "icon-email"},
+	}, cfg.Profile.Links, "lists replace the defaults rather than merging")
+	assert.Equal(t, "someone", cfg.GitHub.Username)
+}
+
+func TestLoadFileFromEnv(t *testing.T) {
+	path := writeConfig(t, "site.yml", "github:\n  username: fromfile\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, "fromfile", cfg.GitHub.Username)
+}
+
+func TestEnvOverridesFile(t *testing.T) {
+	path := writeConfig(t, "site.yaml", `
+server:
+  address: ":9090"
+  idle_timeout: 30s
+github:
+  username: fromfile
+`)
+	t.Setenv("SERVER_ADDRESS", ":7070")
+	t.Setenv("IDLE_TIMEOUT", "2m")
+
+	cfg, err := Load(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, ":7070", cfg.Server.Address)
+	assert.Equal(t, 2*time.Minute, cfg.Server.IdleTimeout)
+	assert.Equal(t, "fromfile", cfg.GitHub.Username)
+}
+
+func TestEnvListOverridesFile(t *testing.T) {
+	path := writeConfig(t, "site.yaml", "github:\n  hidden_events: [WatchEvent]\n")
+	t.Setenv("GITHUB_HIDDEN_EVENTS", "ForkEvent, GollumEvent,")
+
+	cfg, err := Load(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ForkEvent", "GollumEvent"}, cfg.GitHub.HiddenEvents)
+}
+
+func TestEnvOverridesRepoSelection(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("GITHUB_REPOS_INCLUDE", "site,breeze*")
+	t.Setenv("GITHUB_REPOS_SORT", "stars")
+	t.Setenv("GITHUB_REPOS_COUNT", "9")
+
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"site", "breeze*"}, cfg.GitHub.Repos.Include)
+	assert.Equal(t, "stars", cfg.GitHub.Repos.Sort)
+	assert.Equal(t, 9, cfg.GitHub.Repos.Count)
+	assert.True(t, cfg.GitHub.Repos.SkipForks, "defaults are kept")
+}
+
+func TestEnvEnablesForges(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("GITLAB_USERNAME", "someone")
+	t.Setenv("GITEA_USERNAME", "someone")
+	t.Setenv("GITEA_URL", "https://git.example.com")
+
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, "someone", cfg.GitLab.Username)
+	assert.Equal(t, "https://gitlab.com", cfg.GitLab.URL)
+	assert.Equal(t, "https://git.example.com", cfg.Gitea.URL)
+	assert.Equal(t, time.Hour, cfg.Gitea.ReposRefresh)
+}
+
+func TestEnvSelectsWeatherProvider(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("WEATHER_PROVIDER", "open-meteo")
+	t.Setenv("BREEZE_API_KEY", "breeze_key")
+	t.Setenv("BREEZE_API_URL", "http://breeze:8080")
+
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, "open-meteo", cfg.Weather.Provider)
+	assert.Empty(t, cfg.Weather.APIKey, "BREEZE_API_KEY is only for breeze")
+	assert.Empty(t, cfg.Weather.URL, "left to Open-Meteo's default, not pointed at breeze")
+	assert.Empty(t, cfg.Weather.GeocodingURL)
+}
+
+func TestEnvBreezeAliases(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("BREEZE_API_KEY", "old")
+	t.Setenv("BREEZE_API_URL", "http://breeze:8080")
+
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, "breeze", cfg.Weather.Provider)
+	assert.Equal(t, "old", cfg.Weather.APIKey)
+	assert.Equal(t, "http://breeze:8080", cfg.Weather.URL)
+
+	t.Setenv("WEATHER_API_KEY", "new")
+	t.Setenv("WEATHER_API_URL", "http://localhost:9090")
+
+	cfg, err = Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, "new", cfg.Weather.APIKey, "WEATHER_API_KEY wins over BREEZE_API_KEY")
+	assert.Equal(t, "http://localhost:9090", cfg.Weather.URL)
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	path := writeConfig(t, "site.yaml", `
+server:
+  read_timeout: -1s
+  base_url: not a url
+blog:
+  url_prefix: blog
+  theme: neon
+profile:
+  links:
+    - name: Email
+      url: "mailto:Joe <joe@example.com>"
+      icon: icon-email
+    - name: LinkedIn
+      url: "linkedin.com/in/someone"
+    - url: https://example.com
+      icon: icon-link
+github:
+  username: ""
+  hidden_events: [stars]
+  repos:
+    list: pinned
+    exclude: ["[abc"]
+    sort: random
+    count: 0
+gitlab:
+  username: someone
+  url: gitlab.example.com
+gitea:
+  url: ignored until a username is set
+weather:
+  provider: metoffice
+  url: localhost:8080
+`)
+	t.Setenv("WRITE_TIMEOUT", "soon")
+
+	_, err := Load(path)
+
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "expected a ValidationError, got %v", err)
+
+	var fields []string
+	for _, p := range verr.Problems {
+		fields = append(fields, p.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"WRITE_TIMEOUT",
+		"server.read_timeout",
+		"server.base_url",
+		"blog.url_prefix",
+		"blog.theme",
+		"profile.links[0].url",
+		"profile.links[1].url",
+		"profile.links[1].icon",
+		"profile.links[2].name",
+		"github.username",
+		"github.hidden_events[0]",
+		"github.repos.list",
+		"github.repos.exclude[0]",
+		"github.repos.sort",
+		"github.repos.count",
+		"gitlab.url",
+		"weather.provider",
+		"weather.url",
+	}, fields)
+	assert.Contains(t, err.Error(), "server.read_timeout: must be a positive duration")
+}
+
+func TestLoadChecksLinkIconsAgainstSprite(t *testing.T) {
+	staticDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(staticDir, "icons"), 0o755))
+	sprite := `<svg><symbol id="icon-github" viewBox="0 0 24 24"></symbol></svg>`
+	require.NoError(t, os.WriteFile(filepath.Join(staticDir, "icons", "icons.svg"), []byte(sprite), 0o644))
+
+	path := writeConfig(t, "site.yaml", `
+server:
+  static_dir: `+staticDir+`
+profile:
+  links:
+    - name: GitHub
+      url: https://github.com/someone
+      icon: icon-github
+    - name: Bluesky
+      url: https://bsky.app/profile/someone
+      icon: icon-bluesky
+`)
+
+	_, err := Load(path)
+
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr), "expected a ValidationError, got %v", err)
+	assert.Equal(t, []FieldError{
+		{Field: "profile.links[1].icon", Message: `no symbol "icon-bluesky" in icons.svg`},
+	}, verr.Problems)
+}
+
+func TestLoadRejectsUnknownFields(t *testing.T) {
+	path := writeConfig(t, "site.yaml", "server:\n  adress: \":9090\"\n")
+
+	_, err := Load(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "adress")
+}
+
+func TestLoadRejectsUnsupportedFormat(t *testing.T) {
+	path := writeConfig(t, "site.toml", "[server]\n")
+
+	_, err := Load(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format")
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -2,6 +2,8 @@
 package logging
 
 import (
+	"fmt"
+
 	"go.uber.org/zap"
 )
 
@@ -20,26 +22,37 @@ func NewLogger() *Logger {
 	return Log
 }
 
+// Error, Info, Warn and Debug log through Log, if there is one, with
+// secrets redacted. See Redact.
 func Error(msg string, err error) {
 	if Log != nil {
-		Log.Errorw(msg, "error", err)
+		Log.Errorw(Redact(msg), "error", RedactError(err))
 	}
 }
 
 func Info(msg string, args ...any) {
 	if Log != nil {
-		Log.Infof(msg, args...)
+		Log.Info(format(msg, args))
 	}
 }
 
 func Warn(msg string, args ...any) {
 	if Log != nil {
-		Log.Warnf(msg, args...)
+		Log.Warn(format(msg, args))
 	}
 }
 
 func Debug(msg string, args ...any) {
 	if Log != nil {
-		Log.Debugf(msg, args...)
+		Log.Debug(format(msg, args))
+	}
+}
+
+// format formats msg like the Sugared logger's Infof and friends, then
+// redacts it.
+func format(msg string, args []any) string {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
 	}
+	return Redact(msg)
 }
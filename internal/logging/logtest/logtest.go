@@ -0,0 +1,39 @@
+// Package logtest captures what's logged through the logging package, for
+// tests that check what does or doesn't end up in the logs.
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+)
+
+// Logs is everything logged since Capture.
+type Logs struct {
+	*observer.ObservedLogs
+}
+
+// Capture points logging.Log at an in-memory logger until the test ends.
+func Capture(t testing.TB) *Logs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := logging.Log
+	logging.Log = &logging.Logger{SugaredLogger: zap.New(core).Sugar()}
+	t.Cleanup(func() { logging.Log = prev })
+	return &Logs{logs}
+}
+
+// String joins every entry, fields and all, one per line.
+func (l *Logs) String() string {
+	var s strings.Builder
+	for _, entry := range l.All() {
+		fmt.Fprintln(&s, entry.Message, entry.ContextMap())
+	}
+	return s.String()
+}
@@ -16,7 +16,7 @@ func Middleware(next http.Handler) http.Handler {
 			"HTTP Request",
 			"remote_addr", r.RemoteAddr,
 			"method", r.Method,
-			"uri", r.RequestURI,
+			"uri", Redact(r.RequestURI),
 			"status", wrw.statusCode,
 			"duration", duration,
 		)
@@ -0,0 +1,22 @@
+package models
+
+// Forges that repositories and activity can come from. Gitea also covers
+// Forgejo and Codeberg, which share its API.
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+	ForgeGitea  = "gitea"
+)
+
+// ForgeName returns the display name of a forge, treating an empty forge as
+// GitHub.
+func ForgeName(forge string) string {
+	switch forge {
+	case ForgeGitLab:
+		return "GitLab"
+	case ForgeGitea:
+		return "Gitea"
+	default:
+		return "GitHub"
+	}
+}
@@ -5,31 +5,106 @@ import (
 	"time"
 )
 
+// Repository is a repository card. The JSON tags follow GitHub's REST API,
+// which it's decoded from directly; other forges fill it in by hand.
 type Repository struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	URL         string    `json:"html_url"`
-	Language    string    `json:"language"`
-	Stars       int       `json:"stargazers_count"`
-	Forks       int       `json:"forks_count"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// Forge is the models.Forge constant for where the repository lives.
+	// Snapshots from before forges were tracked leave it empty, meaning
+	// GitHub.
+	Forge         string    `json:"forge,omitempty"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	URL           string    `json:"html_url"`
+	Language      string    `json:"language"`
+	LanguageColor string    `json:"language_color,omitempty"`
+	Topics        []string  `json:"topics,omitempty"`
+	Stars         int       `json:"stargazers_count"`
+	Forks         int       `json:"forks_count"`
+	Fork          bool      `json:"fork"`
+	Archived      bool      `json:"archived"`
+	IsTemplate    bool      `json:"is_template"`
+	Pinned        bool      `json:"pinned,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	PushedAt      time.Time `json:"pushed_at"`
 }
 
+// Activity is a single entry in the activity timeline. URL links to the
+// repository; Detail and DetailURL describe and link to the specific commit,
+// pull request, issue or release when the event has one.
+//
+// Consecutive events of the same type in the same repository are grouped
+// into one Activity: Count is the number of events, CreatedAt the newest and
+// Since the oldest, while the detail describes the newest.
 type Activity struct {
+	Forge     string    `json:"forge,omitempty"`
 	Type      string    `json:"type"`
 	RepoName  string    `json:"repo"`
 	CreatedAt time.Time `json:"created_at"`
+	Since     time.Time `json:"since,omitzero"`
+	Count     int       `json:"count,omitempty"`
 	Action    string    `json:"action"`
 	URL       string    `json:"url"`
+	Detail    string    `json:"detail,omitempty"`
+	DetailURL string    `json:"detail_url,omitempty"`
 }
 
-type GitHubEventResponse []struct {
+type GitHubEvent struct {
 	Type string `json:"type"`
 	Repo struct {
 		Name string `json:"name"`
 	} `json:"repo"`
-	CreatedAt time.Time `json:"created_at"`
-	Payload   json.RawMessage
+	CreatedAt time.Time       `json:"created_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+type GitHubEventResponse []GitHubEvent
+
+// Payloads of the events API, trimmed to the fields we display. See
+// https://docs.github.com/en/rest/using-the-rest-api/github-event-types.
+
+type PushEventPayload struct {
+	Ref     string `json:"ref"`
+	Head    string `json:"head"`
+	Before  string `json:"before"`
+	Size    int    `json:"size"`
+	Commits []struct {
+		SHA     string `json:"sha"`
+		Message string `json:"message"`
+	} `json:"commits"`
+}
+
+type PullRequestEventPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Merged  bool   `json:"merged"`
+	} `json:"pull_request"`
+}
+
+type IssuesEventPayload struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	} `json:"issue"`
+}
+
+type ReleaseEventPayload struct {
+	Action  string `json:"action"`
+	Release struct {
+		TagName string `json:"tag_name"`
+		Name    string `json:"name"`
+		HTMLURL string `json:"html_url"`
+	} `json:"release"`
+}
+
+type CreateEventPayload struct {
+	Ref         string `json:"ref"`
+	RefType     string `json:"ref_type"`
+	Description string `json:"description"`
 }
 
 func MapActivityAction(eventType string) string {
@@ -52,3 +127,38 @@ func MapActivityAction(eventType string) string {
 		return "worked on"
 	}
 }
+
+// GitHubStats summarises the user's repositories: their languages weighted
+// by bytes of code, and totals across the profile.
+type GitHubStats struct {
+	Languages   []LanguageShare `json:"languages"`
+	PublicRepos int             `json:"public_repos"`
+	Stars       int             `json:"stars"`
+	Forks       int             `json:"forks"`
+	Followers   int             `json:"followers"`
+}
+
+type LanguageShare struct {
+	Name    string  `json:"name"`
+	Bytes   int64   `json:"bytes"`
+	Percent float64 `json:"percent"`
+}
+
+// ContributionCalendar is a year of contributions, in weeks starting on
+// Sunday. The first and last weeks may be partial.
+type ContributionCalendar struct {
+	Total int                `json:"total"`
+	Weeks []ContributionWeek `json:"weeks"`
+}
+
+type ContributionWeek struct {
+	Days []ContributionDay `json:"days"`
+}
+
+// ContributionDay is one day of the calendar. Level buckets Count from 0
+// (none) to 4 (the busiest quartile), as GitHub shades its own heatmap.
+type ContributionDay struct {
+	Date  time.Time `json:"date"`
+	Count int       `json:"count"`
+	Level int       `json:"level"`
+}
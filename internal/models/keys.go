@@ -0,0 +1,28 @@
+package models
+
+import "strings"
+
+// Keys under which the built-in data sources store their results.
+const (
+	KeyRepositories  = "repositories"
+	KeyActivity      = "activity"
+	KeyWeather       = "weather"
+	KeyStats         = "stats"
+	KeyContributions = "contributions"
+)
+
+// ForgeKey is the key for a forge's share of a merged result, such as
+// "repositories:gitlab". GitHub keeps the plain key, so existing snapshots
+// and webhooks still find its results.
+func ForgeKey(key, forge string) string {
+	if forge == "" || forge == ForgeGitHub {
+		return key
+	}
+	return key + ":" + forge
+}
+
+// BaseKey strips the forge from a key made by ForgeKey.
+func BaseKey(key string) string {
+	base, _, _ := strings.Cut(key, ":")
+	return base
+}
@@ -0,0 +1,16 @@
+package models
+
+import "strings"
+
+// Link is a social or profile link shown under the profile picture.
+type Link struct {
+	Name string   `json:"name" yaml:"name"`
+	URL  string   `json:"url" yaml:"url"`
+	Icon string   `json:"icon" yaml:"icon"` // symbol id in static/icons/icons.svg
+	Rel  []string `json:"rel,omitempty" yaml:"rel"`
+}
+
+// RelAttr returns the link's rel values joined for use in an HTML attribute.
+func (l Link) RelAttr() string {
+	return strings.Join(l.Rel, " ")
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// CoordinatePrecision is how many decimal places of latitude and longitude
+// are kept. One place is roughly 11km, enough to pick the right city's
+// weather without publishing where exactly someone is.
+const CoordinatePrecision = 1
+
+// Coordinates is a point in decimal degrees.
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Coarsen rounds the coordinates to CoordinatePrecision decimal places.
+func (c Coordinates) Coarsen() Coordinates {
+	scale := math.Pow10(CoordinatePrecision)
+	round := func(v float64) float64 {
+		// Adding zero turns -0 into 0 so it doesn't show as "-0.0".
+		return math.Round(v*scale)/scale + 0
+	}
+	return Coordinates{Lat: round(c.Lat), Lon: round(c.Lon)}
+}
+
+// Valid reports whether the coordinates are on the globe.
+func (c Coordinates) Valid() bool {
+	return c.Lat >= -90 && c.Lat <= 90 && c.Lon >= -180 && c.Lon <= 180
+}
+
+func (c Coordinates) String() string {
+	ns, ew := "N", "E"
+	if c.Lat < 0 {
+		ns = "S"
+	}
+	if c.Lon < 0 {
+		ew = "W"
+	}
+	return fmt.Sprintf("%.*f°%s, %.*f°%s",
+		CoordinatePrecision, math.Abs(c.Lat), ns, CoordinatePrecision, math.Abs(c.Lon), ew)
+}
+
+// Location is where the weather is reported for: a place name, coordinates,
+// or both. UpdatedAt is unset for the configured default location.
+type Location struct {
+	Name        string       `json:"name,omitempty"`
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+	UpdatedAt   time.Time    `json:"updated_at,omitzero"`
+}
+
+// IsZero reports whether no location is known.
+func (l Location) IsZero() bool {
+	return l.Name == "" && l.Coordinates == nil
+}
+
+// Query is what a weather API is asked for: the name if there is one,
+// otherwise "lat,lon".
+func (l Location) Query() string {
+	if l.Name != "" || l.Coordinates == nil {
+		return l.Name
+	}
+	return strconv.FormatFloat(l.Coordinates.Lat, 'f', -1, 64) + "," +
+		strconv.FormatFloat(l.Coordinates.Lon, 'f', -1, 64)
+}
+
+// String is the location as shown on the page.
+func (l Location) String() string {
+	if l.Name != "" || l.Coordinates == nil {
+		return l.Name
+	}
+	return l.Coordinates.String()
+}
+
+// Same reports whether l and other describe the same place, ignoring when
+// they were set.
+func (l Location) Same(other Location) bool {
+	if l.Name != other.Name || (l.Coordinates == nil) != (other.Coordinates == nil) {
+		return false
+	}
+	return l.Coordinates == nil || *l.Coordinates == *other.Coordinates
+}
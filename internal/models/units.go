@@ -0,0 +1,78 @@
+package models
+
+// Units is a system of measurement to show the weather in. WeatherData is
+// always stored in metric and converted with In for display.
+type Units string
+
+const (
+	Metric   Units = "metric"
+	Imperial Units = "imperial"
+)
+
+// ParseUnits reports whether s names a known system of units.
+func ParseUnits(s string) (Units, bool) {
+	switch u := Units(s); u {
+	case Metric, Imperial:
+		return u, true
+	default:
+		return "", false
+	}
+}
+
+// Other is the system a units toggle switches to.
+func (u Units) Other() Units {
+	if u == Imperial {
+		return Metric
+	}
+	return Imperial
+}
+
+// TemperatureUnit is the symbol shown after temperatures.
+func (u Units) TemperatureUnit() string {
+	if u == Imperial {
+		return "°F"
+	}
+	return "°C"
+}
+
+// SpeedUnit is the symbol shown after wind speeds.
+func (u Units) SpeedUnit() string {
+	if u == Imperial {
+		return "mph"
+	}
+	return "m/s"
+}
+
+func (u Units) temperature(celsius float64) float64 {
+	if u == Imperial {
+		return celsius*9/5 + 32
+	}
+	return celsius
+}
+
+func (u Units) speed(metresPerSecond float64) float64 {
+	if u == Imperial {
+		return metresPerSecond * 3600 / 1609.344
+	}
+	return metresPerSecond
+}
+
+// In returns a copy of w with temperatures and wind speed in units.
+func (w WeatherData) In(units Units) WeatherData {
+	w.Temperature = units.temperature(w.Temperature)
+	w.FeelsLike = units.temperature(w.FeelsLike)
+	w.High = units.temperature(w.High)
+	w.Low = units.temperature(w.Low)
+	w.WindSpeed = units.speed(w.WindSpeed)
+
+	if w.Forecast != nil {
+		forecast := make([]DailyForecast, len(w.Forecast))
+		for i, day := range w.Forecast {
+			day.High = units.temperature(day.High)
+			day.Low = units.temperature(day.Low)
+			forecast[i] = day
+		}
+		w.Forecast = forecast
+	}
+	return w
+}
@@ -2,10 +2,64 @@ package models
 
 import "time"
 
+// WeatherData is the current weather at Location, in metric units. Sunrise
+// and Sunset are in the location's time zone, High and Low are today's
+// forecast and Forecast covers the days after today.
 type WeatherData struct {
-	Location    string    `json:"location"`
-	Temperature float64   `json:"temperature"`
-	Condition   string    `json:"condition"`
-	Icon        string    `json:"icon"`
+	Location    string  `json:"location"`
+	Temperature float64 `json:"temperature"`
+	FeelsLike   float64 `json:"feels_like"`
+	High        float64 `json:"high"`
+	Low         float64 `json:"low"`
+	// Humidity is relative humidity as a percentage.
+	Humidity int `json:"humidity"`
+	// WindSpeed is in metres per second, and WindDirection is the bearing
+	// the wind blows from, in degrees.
+	WindSpeed     float64         `json:"wind_speed"`
+	WindDirection int             `json:"wind_direction"`
+	Sunrise       time.Time       `json:"sunrise,omitzero"`
+	Sunset        time.Time       `json:"sunset,omitzero"`
+	Condition     string          `json:"condition"`
+	Code          string          `json:"code"`
+	Forecast      []DailyForecast `json:"forecast,omitempty"`
+	// Provider and ProviderURL credit where the weather came from.
+	Provider    string    `json:"provider,omitempty"`
+	ProviderURL string    `json:"provider_url,omitempty"`
 	LastUpdated time.Time `json:"last_updated"`
 }
+
+// Weather condition codes, as set in WeatherData.Code. Each is drawn by a
+// "weather-<code>" symbol in static/icons/icons.svg.
+const (
+	WeatherClearDay          = "clear-day"
+	WeatherClearNight        = "clear-night"
+	WeatherPartlyCloudyDay   = "partly-cloudy-day"
+	WeatherPartlyCloudyNight = "partly-cloudy-night"
+	WeatherCloudy            = "cloudy"
+	WeatherShowers           = "showers"
+	WeatherRain              = "rain"
+	WeatherThunderstorm      = "thunderstorm"
+	WeatherSnow              = "snow"
+	WeatherFog               = "fog"
+)
+
+// WeatherCodes lists every condition code.
+var WeatherCodes = []string{
+	WeatherClearDay, WeatherClearNight, WeatherPartlyCloudyDay, WeatherPartlyCloudyNight,
+	WeatherCloudy, WeatherShowers, WeatherRain, WeatherThunderstorm, WeatherSnow, WeatherFog,
+}
+
+// DailyForecast is one day's forecast. Date is midday in the location's time
+// zone.
+type DailyForecast struct {
+	Date      time.Time `json:"date"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Condition string    `json:"condition"`
+	Code      string    `json:"code"`
+}
+
+// Outlook returns up to the next n days of the forecast.
+func (w WeatherData) Outlook(n int) []DailyForecast {
+	return w.Forecast[:min(n, len(w.Forecast))]
+}
@@ -0,0 +1,118 @@
+// Package forge holds what the GitHub, GitLab and Gitea clients share: the
+// Forge interface, repository selection, activity grouping and the
+// DataUpdater sources built on them.
+package forge
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+const (
+	// MaxActivities is how many entries a forge's timeline shows.
+	MaxActivities = 6
+	// MaxDetailLength caps commit messages and titles so one long line can't
+	// stretch the timeline.
+	MaxDetailLength = 72
+	// EventsPerPage is how many events clients ask a forge for: more than
+	// MaxActivities, since grouping and hiding them can leave far fewer.
+	EventsPerPage = 30
+)
+
+// Filter is what a forge client shows: the repositories Repos picks, and
+// activity other than the HiddenEvents types, named as GitHub names its
+// events. Every forge is given the same one.
+type Filter struct {
+	Repos        RepoSelection
+	HiddenEvents []string
+}
+
+// DefaultFilter shows DefaultRepoSelection and every kind of activity.
+var DefaultFilter = Filter{Repos: DefaultRepoSelection}
+
+// Hides reports whether activities of eventType are left out.
+func (f Filter) Hides(eventType string) bool {
+	return slices.Contains(f.HiddenEvents, eventType)
+}
+
+// Forge is a code hosting service we list repositories and activity from.
+// Activity types use GitHub's event names, such as "PushEvent", whatever the
+// forge calls them, so hidden events and the templates treat every forge
+// alike.
+type Forge interface {
+	// ID is one of the models.Forge constants. It names the forge's icon
+	// and separates its results from other forges'.
+	ID() string
+	// Name is shown to people, as in "GitLab repositories".
+	Name() string
+	FetchRepositories(ctx context.Context) ([]models.Repository, error)
+	FetchActivity(ctx context.Context) ([]models.Activity, error)
+}
+
+// Timeline groups activities and keeps the newest MaxActivities entries.
+// Activities are expected newest first, with hidden types already left out
+// by the client's Filter.
+func Timeline(activities []models.Activity) []models.Activity {
+	activities = GroupActivities(activities)
+	if len(activities) > MaxActivities {
+		activities = activities[:MaxActivities]
+	}
+	return activities
+}
+
+// GroupActivities merges runs of consecutive activities with the same type
+// and repository, such as several pushes in a row, into a single entry.
+// Activities are expected newest first, as the events APIs return them.
+func GroupActivities(activities []models.Activity) []models.Activity {
+	grouped := make([]models.Activity, 0, len(activities))
+	for _, a := range activities {
+		if n := len(grouped); n > 0 {
+			last := &grouped[n-1]
+			if last.Type == a.Type && last.RepoName == a.RepoName {
+				last.Count++
+				last.Since = a.CreatedAt
+				continue
+			}
+		}
+		a.Count = 1
+		a.Since = a.CreatedAt
+		grouped = append(grouped, a)
+	}
+	return grouped
+}
+
+// NextPageURL extracts the rel="next" URL from a Link header such as
+// <https://api.github.com/user/1/repos?page=2>; rel="next", <...>; rel="last".
+// GitHub, GitLab and Gitea all paginate this way.
+func NextPageURL(link string) string {
+	for part := range strings.SplitSeq(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok {
+			continue
+		}
+		for param := range strings.SplitSeq(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// FirstLine returns the first line of a commit message, truncated.
+func FirstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return Truncate(strings.TrimSpace(line))
+}
+
+// Truncate shortens s to MaxDetailLength runes, ending it with an ellipsis.
+func Truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= MaxDetailLength {
+		return s
+	}
+	return strings.TrimSpace(string(r[:MaxDetailLength-1])) + "…"
+}
@@ -0,0 +1,80 @@
+package forge
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+func TestGroupActivities(t *testing.T) {
+	now := time.Now()
+	activity := func(eventType, repo string, ago time.Duration) models.Activity {
+		return models.Activity{Type: eventType, RepoName: repo, CreatedAt: now.Add(-ago)}
+	}
+
+	grouped := GroupActivities([]models.Activity{
+		activity("PushEvent", "user/repo1", 1*time.Hour),
+		activity("PushEvent", "user/repo1", 2*time.Hour),
+		activity("PushEvent", "user/repo1", 3*time.Hour),
+		activity("PushEvent", "user/repo2", 4*time.Hour),
+		activity("IssuesEvent", "user/repo2", 5*time.Hour),
+		activity("PushEvent", "user/repo1", 6*time.Hour),
+	})
+
+	require.Len(t, grouped, 4)
+	assert.Equal(t, 3, grouped[0].Count)
+	assert.Equal(t, now.Add(-1*time.Hour), grouped[0].CreatedAt)
+	assert.Equal(t, now.Add(-3*time.Hour), grouped[0].Since)
+	for _, a := range grouped[1:] {
+		assert.Equal(t, 1, a.Count, "%s in %s", a.Type, a.RepoName)
+		assert.Equal(t, a.CreatedAt, a.Since)
+	}
+	assert.Equal(t, "user/repo1", grouped[3].RepoName, "only consecutive events are grouped")
+}
+
+func TestTimeline(t *testing.T) {
+	now := time.Now()
+	var activities []models.Activity
+	for i := range 10 {
+		repo := fmt.Sprintf("user/repo%d", i)
+		activities = append(activities,
+			models.Activity{Type: "PushEvent", RepoName: repo, CreatedAt: now.Add(-time.Duration(2*i) * time.Hour)},
+			models.Activity{Type: "PushEvent", RepoName: repo, CreatedAt: now.Add(-time.Duration(2*i+1) * time.Hour)},
+		)
+	}
+
+	timeline := Timeline(activities)
+
+	require.Len(t, timeline, MaxActivities, "capped after grouping")
+	for i, a := range timeline {
+		assert.Equal(t, fmt.Sprintf("user/repo%d", i), a.RepoName)
+		assert.Equal(t, 2, a.Count)
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	tests := map[string]string{
+		"": "",
+		`<https://api.github.com/user/1/repos?page=2>; rel="next", <https://api.github.com/user/1/repos?page=5>; rel="last"`:  "https://api.github.com/user/1/repos?page=2",
+		`<https://api.github.com/user/1/repos?page=1>; rel="prev", <https://api.github.com/user/1/repos?page=1>; rel="first"`: "",
+	}
+
+	for link, want := range tests {
+		assert.Equal(t, want, NextPageURL(link), link)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("x", MaxDetailLength+10)
+
+	assert.Equal(t, "short", Truncate("short"))
+	assert.Len(t, []rune(Truncate(long)), MaxDetailLength)
+	assert.True(t, strings.HasSuffix(Truncate(long), "…"))
+	assert.Equal(t, "Fix the thing", FirstLine("  Fix the thing\n\nLonger explanation"))
+}
@@ -0,0 +1,14 @@
+// Package forgetest holds helpers for testing the forge clients.
+package forgetest
+
+import "github.com/josephburgess/joeburgess.dev/internal/models"
+
+// RepoNames returns the names of repos, in order, for comparing lists of
+// repositories in tests.
+func RepoNames(repos []models.Repository) []string {
+	names := make([]string, len(repos))
+	for i, repo := range repos {
+		names[i] = repo.Name
+	}
+	return names
+}
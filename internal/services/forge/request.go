@@ -0,0 +1,49 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// StatusError is returned by GetPage for an unsuccessful response.
+type StatusError struct {
+	Forge      string
+	Status     string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s API returned status: %s", e.Forge, e.Status)
+}
+
+// GetPage fetches url with header, as the forge named name, and decodes the
+// JSON response into v. It returns the URL of the next page from the Link
+// header, or "" on the last page.
+//
+// The GitHub client has its own, with conditional requests and rate limits;
+// GitLab and Gitea are asked far less often and don't need them.
+func GetPage(ctx context.Context, hc *http.Client, name, url string, header http.Header, v any) (next string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, vals := range header {
+		req.Header[k] = vals
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{Forge: name, Status: resp.Status, StatusCode: resp.StatusCode}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("decoding %s response: %w", name, err)
+	}
+	return NextPageURL(resp.Header.Get("Link")), nil
+}
@@ -0,0 +1,108 @@
+package forge
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// Orders for RepoSelection.Sort.
+const (
+	SortUpdated = "updated"
+	SortPushed  = "pushed"
+	SortStars   = "stars"
+	SortName    = "name"
+)
+
+// SortOrders lists the valid values of RepoSelection.Sort.
+var SortOrders = []string{SortUpdated, SortPushed, SortStars, SortName}
+
+// RepoSelection decides which of the user's repositories are shown, and in
+// what order.
+type RepoSelection struct {
+	// Include and Exclude are glob patterns, as in path.Match, matched
+	// against repository names. An empty Include matches everything, and
+	// Exclude wins over Include.
+	Include []string
+	Exclude []string
+
+	SkipForks     bool
+	SkipArchived  bool
+	SkipTemplates bool
+	MinStars      int
+
+	// Sort is one of SortOrders; updated, pushed and stars list the most
+	// recent or popular first. Defaults to SortUpdated.
+	Sort string
+	// Count caps the number of repositories shown. Defaults to 6.
+	Count int
+}
+
+// DefaultRepoSelection shows the six most recently updated repositories.
+var DefaultRepoSelection = RepoSelection{Sort: SortUpdated, Count: 6}
+
+// Select returns the repositories picked by s, in order.
+func (s RepoSelection) Select(repos []models.Repository) []models.Repository {
+	selected := make([]models.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if s.includes(repo) {
+			selected = append(selected, repo)
+		}
+	}
+
+	slices.SortStableFunc(selected, s.Compare)
+
+	count := s.Count
+	if count <= 0 {
+		count = DefaultRepoSelection.Count
+	}
+	if len(selected) > count {
+		selected = selected[:count]
+	}
+	return selected
+}
+
+func (s RepoSelection) includes(repo models.Repository) bool {
+	switch {
+	case s.SkipForks && repo.Fork,
+		s.SkipArchived && repo.Archived,
+		s.SkipTemplates && repo.IsTemplate,
+		repo.Stars < s.MinStars:
+		return false
+	}
+	if len(s.Include) > 0 && !matchAny(s.Include, repo.Name) {
+		return false
+	}
+	return !matchAny(s.Exclude, repo.Name)
+}
+
+// Compare orders a and b as s.Sort says, for sorting with slices.SortFunc.
+func (s RepoSelection) Compare(a, b models.Repository) int {
+	switch s.Sort {
+	case SortPushed:
+		return b.PushedAt.Compare(a.PushedAt)
+	case SortStars:
+		if c := cmp.Compare(b.Stars, a.Stars); c != 0 {
+			return c
+		}
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	case SortName:
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	default:
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	}
+}
+
+// matchAny reports whether name matches any of patterns. Patterns are
+// validated when the config is loaded, so a malformed one never matches.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
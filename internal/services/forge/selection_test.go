@@ -0,0 +1,76 @@
+package forge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge/forgetest"
+)
+
+func TestRepoSelection(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2023, 1, n, 0, 0, 0, 0, time.UTC) }
+	repos := []models.Repository{
+		{Name: "site", Stars: 5, UpdatedAt: day(5), PushedAt: day(1)},
+		{Name: "cli-tool", Stars: 40, UpdatedAt: day(4), PushedAt: day(4)},
+		{Name: "forked-lib", Stars: 2, Fork: true, UpdatedAt: day(6), PushedAt: day(6)},
+		{Name: "old-thing", Stars: 9, Archived: true, UpdatedAt: day(3), PushedAt: day(3)},
+		{Name: "starter", Stars: 1, IsTemplate: true, UpdatedAt: day(2), PushedAt: day(5)},
+		{Name: "Breeze", Stars: 12, UpdatedAt: day(1), PushedAt: day(2)},
+	}
+
+	tests := []struct {
+		name string
+		sel  RepoSelection
+		want []string
+	}{
+		{
+			name: "default",
+			sel:  DefaultRepoSelection,
+			want: []string{"forked-lib", "site", "cli-tool", "old-thing", "starter", "Breeze"},
+		},
+		{
+			name: "skip forks, archived and templates",
+			sel:  RepoSelection{SkipForks: true, SkipArchived: true, SkipTemplates: true},
+			want: []string{"site", "cli-tool", "Breeze"},
+		},
+		{
+			name: "include and exclude patterns",
+			sel:  RepoSelection{Include: []string{"*e*"}, Exclude: []string{"old-*", "starter"}},
+			want: []string{"forked-lib", "site", "Breeze"},
+		},
+		{
+			name: "minimum stars",
+			sel:  RepoSelection{MinStars: 9},
+			want: []string{"cli-tool", "old-thing", "Breeze"},
+		},
+		{
+			name: "sort by stars",
+			sel:  RepoSelection{Sort: SortStars, Count: 3},
+			want: []string{"cli-tool", "Breeze", "old-thing"},
+		},
+		{
+			name: "sort by pushed",
+			sel:  RepoSelection{Sort: SortPushed, Count: 2},
+			want: []string{"forked-lib", "starter"},
+		},
+		{
+			name: "sort by name",
+			sel:  RepoSelection{Sort: SortName, SkipForks: true},
+			want: []string{"Breeze", "cli-tool", "old-thing", "site", "starter"},
+		},
+		{
+			name: "count applies after filtering",
+			sel:  RepoSelection{SkipForks: true, Count: 2},
+			want: []string{"site", "cli-tool"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, forgetest.RepoNames(tc.sel.Select(repos)))
+		})
+	}
+}
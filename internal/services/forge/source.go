@@ -0,0 +1,56 @@
+package forge
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// RepositoriesSource exposes a forge's FetchRepositories as a DataUpdater
+// source. Its results are stored under models.ForgeKey, so DataUpdater can
+// merge the lists from every forge.
+type RepositoriesSource struct {
+	forge    Forge
+	interval time.Duration
+}
+
+func NewRepositoriesSource(f Forge, interval time.Duration) *RepositoriesSource {
+	return &RepositoriesSource{forge: f, interval: interval}
+}
+
+func (s *RepositoriesSource) Name() string { return s.forge.Name() + " repositories" }
+func (s *RepositoriesSource) Key() string {
+	return models.ForgeKey(models.KeyRepositories, s.forge.ID())
+}
+func (s *RepositoriesSource) Interval() time.Duration { return s.interval }
+
+func (s *RepositoriesSource) Fetch(ctx context.Context) (any, error) {
+	repos, err := s.forge.FetchRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+// ActivitySource exposes a forge's FetchActivity as a DataUpdater source.
+type ActivitySource struct {
+	forge    Forge
+	interval time.Duration
+}
+
+func NewActivitySource(f Forge, interval time.Duration) *ActivitySource {
+	return &ActivitySource{forge: f, interval: interval}
+}
+
+func (s *ActivitySource) Name() string            { return s.forge.Name() + " activity" }
+func (s *ActivitySource) Key() string             { return models.ForgeKey(models.KeyActivity, s.forge.ID()) }
+func (s *ActivitySource) Interval() time.Duration { return s.interval }
+
+func (s *ActivitySource) Fetch(ctx context.Context) (any, error) {
+	activities, err := s.forge.FetchActivity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
@@ -0,0 +1,163 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+// action is an entry from the activity feed, trimmed to the fields we
+// display. Repo is missing for actions on repositories since deleted.
+type action struct {
+	OpType string `json:"op_type"`
+	Repo   *struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repo"`
+	RefName string    `json:"ref_name"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created"`
+}
+
+// pushContent is the JSON in a commit_repo action's content.
+type pushContent struct {
+	Commits []struct {
+		Sha1    string
+		Message string
+	}
+	CompareURL string
+	Len        int
+}
+
+// activityTypes names the feed's op types as GitHub names its events. Types
+// that aren't listed, such as comments and deleted branches, aren't shown.
+var activityTypes = map[string]string{
+	"create_repo":         "CreateEvent",
+	"commit_repo":         "PushEvent",
+	"mirror_sync_push":    "PushEvent",
+	"push_tag":            "CreateEvent",
+	"create_pull_request": "PullRequestEvent",
+	"merge_pull_request":  "PullRequestEvent",
+	"close_pull_request":  "PullRequestEvent",
+	"reopen_pull_request": "PullRequestEvent",
+	"create_issue":        "IssuesEvent",
+	"close_issue":         "IssuesEvent",
+	"reopen_issue":        "IssuesEvent",
+	"publish_release":     "ReleaseEvent",
+	"star_repo":           "WatchEvent",
+	"fork_repo":           "ForkEvent",
+}
+
+func (c *Client) activity(eventType string, a action) models.Activity {
+	repoURL := a.Repo.HTMLURL
+	activity := models.Activity{
+		Forge:     models.ForgeGitea,
+		Type:      eventType,
+		RepoName:  a.Repo.FullName,
+		CreatedAt: a.Created,
+		URL:       repoURL,
+		Action:    models.MapActivityAction(eventType),
+	}
+
+	switch a.OpType {
+	case "commit_repo", "mirror_sync_push":
+		c.describePush(&activity, a)
+	case "push_tag":
+		tag := strings.TrimPrefix(a.RefName, "refs/tags/")
+		activity.Action = "created a tag in"
+		activity.Detail = tag
+		activity.DetailURL = fmt.Sprintf("%s/src/tag/%s", repoURL, tag)
+	case "create_pull_request", "merge_pull_request", "close_pull_request", "reopen_pull_request":
+		activity.Action = opVerb(a.OpType) + " a pull request in"
+		describeIssue(&activity, a.Content, repoURL+"/pulls/")
+	case "create_issue", "close_issue", "reopen_issue":
+		activity.Action = opVerb(a.OpType) + " an issue in"
+		describeIssue(&activity, a.Content, repoURL+"/issues/")
+	case "publish_release":
+		tag := strings.TrimPrefix(a.RefName, "refs/tags/")
+		activity.Action = "released"
+		activity.Detail = tag
+		if name := forge.Truncate(a.Content); name != "" && name != tag {
+			activity.Detail += ": " + name
+		}
+		activity.DetailURL = fmt.Sprintf("%s/releases/tag/%s", repoURL, tag)
+	}
+	return activity
+}
+
+// opVerb turns the verb in an issue or pull request op type, such as
+// "merge_pull_request", into the past tense.
+func opVerb(opType string) string {
+	op, _, _ := strings.Cut(opType, "_")
+	switch op {
+	case "create":
+		return "opened"
+	case "merge":
+		return "merged"
+	case "close":
+		return "closed"
+	case "reopen":
+		return "reopened"
+	default:
+		return op
+	}
+}
+
+// describePush describes a push from its content, which lists the commits
+// newest first.
+func (c *Client) describePush(activity *models.Activity, a action) {
+	branch := strings.TrimPrefix(a.RefName, "refs/heads/")
+	activity.Detail = branch
+	if a.Content == "" {
+		return
+	}
+
+	var p pushContent
+	if err := json.Unmarshal([]byte(a.Content), &p); err != nil {
+		logging.Warn("Failed to decode Gitea push to %s: %v", a.Repo.FullName, err)
+		return
+	}
+
+	count := p.Len
+	if count == 0 {
+		count = len(p.Commits)
+	}
+	switch {
+	case count == 1:
+		activity.Detail = "1 commit to " + branch
+	case count > 1:
+		activity.Detail = fmt.Sprintf("%d commits to %s", count, branch)
+	}
+	if len(p.Commits) > 0 {
+		if msg := forge.FirstLine(p.Commits[0].Message); msg != "" {
+			activity.Detail += ": " + msg
+		}
+	}
+
+	switch {
+	case count > 1 && p.CompareURL != "":
+		// CompareURL is relative to the instance, as in
+		// "user/repo/compare/abc...def".
+		activity.DetailURL = c.baseURL + "/" + strings.TrimPrefix(p.CompareURL, "/")
+	case len(p.Commits) > 0:
+		activity.DetailURL = fmt.Sprintf("%s/commit/%s", activity.URL, p.Commits[0].Sha1)
+	}
+}
+
+// describeIssue describes an issue or pull request from content of the form
+// "12|Title", linking it under prefix.
+func describeIssue(activity *models.Activity, content, prefix string) {
+	index, title, _ := strings.Cut(content, "|")
+	n, err := strconv.Atoi(index)
+	if err != nil {
+		return
+	}
+	activity.Detail = fmt.Sprintf("#%d %s", n, forge.Truncate(title))
+	activity.DetailURL = prefix + strconv.Itoa(n)
+}
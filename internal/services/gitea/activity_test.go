@@ -0,0 +1,111 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+const testFeed = `[
+	{"op_type": "commit_repo", "ref_name": "refs/heads/main", "created": "2023-01-06T00:00:00Z",
+	 "repo": {"full_name": "testuser/site", "html_url": "%[1]s/testuser/site"},
+	 "content": "{\"Commits\":[{\"Sha1\":\"bbb222\",\"Message\":\"Fix the build\\n\"},{\"Sha1\":\"aaa111\",\"Message\":\"Start\"}],\"CompareURL\":\"testuser/site/compare/aaa000...bbb222\",\"Len\":2}"},
+	{"op_type": "merge_pull_request", "created": "2023-01-05T00:00:00Z", "content": "7|Add dark mode",
+	 "repo": {"full_name": "testuser/site", "html_url": "%[1]s/testuser/site"}},
+	{"op_type": "comment_issue", "created": "2023-01-04T00:00:00Z", "content": "3|Nice",
+	 "repo": {"full_name": "testuser/site", "html_url": "%[1]s/testuser/site"}},
+	{"op_type": "create_issue", "created": "2023-01-03T00:00:00Z", "content": "3|Crash on start",
+	 "repo": {"full_name": "other/tool", "html_url": "%[1]s/other/tool"}},
+	{"op_type": "publish_release", "ref_name": "v1.0.0", "content": "First release", "created": "2023-01-02T00:00:00Z",
+	 "repo": {"full_name": "testuser/site", "html_url": "%[1]s/testuser/site"}},
+	{"op_type": "create_repo", "created": "2023-01-01T00:00:00Z"}
+]`
+
+func TestFetchActivity(t *testing.T) {
+	var client *Client
+	client = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/users/testuser/activities/feeds", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("only-performed-by"))
+		fmt.Fprintf(w, testFeed, client.baseURL)
+	})
+	base := client.baseURL
+
+	activities, err := client.FetchActivity(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, activities, 4, "comments and deleted repositories are skipped")
+
+	push := activities[0]
+	assert.Equal(t, models.ForgeGitea, push.Forge)
+	assert.Equal(t, "PushEvent", push.Type)
+	assert.Equal(t, "testuser/site", push.RepoName)
+	assert.Equal(t, "2 commits to main: Fix the build", push.Detail)
+	assert.Equal(t, base+"/testuser/site/compare/aaa000...bbb222", push.DetailURL)
+
+	pr := activities[1]
+	assert.Equal(t, "PullRequestEvent", pr.Type)
+	assert.Equal(t, "merged a pull request in", pr.Action)
+	assert.Equal(t, "#7 Add dark mode", pr.Detail)
+	assert.Equal(t, base+"/testuser/site/pulls/7", pr.DetailURL)
+
+	issue := activities[2]
+	assert.Equal(t, "opened an issue in", issue.Action)
+	assert.Equal(t, base+"/other/tool/issues/3", issue.DetailURL)
+
+	release := activities[3]
+	assert.Equal(t, "released", release.Action)
+	assert.Equal(t, "v1.0.0: First release", release.Detail)
+	assert.Equal(t, base+"/testuser/site/releases/tag/v1.0.0", release.DetailURL)
+}
+
+func TestOpVerb(t *testing.T) {
+	for opType, want := range map[string]string{
+		"create_issue":        "opened",
+		"close_issue":         "closed",
+		"reopen_issue":        "reopened",
+		"create_pull_request": "opened",
+		"merge_pull_request":  "merged",
+		"close_pull_request":  "closed",
+		"reopen_pull_request": "reopened",
+	} {
+		assert.Equal(t, want, opVerb(opType), opType)
+	}
+}
+
+func TestFetchActivityReopened(t *testing.T) {
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"op_type": "reopen_issue", "created": "2023-01-02T00:00:00Z", "content": "3|Crash on start",
+			 "repo": {"full_name": "testuser/site", "html_url": "https://gitea.example.com/testuser/site"}},
+			{"op_type": "reopen_pull_request", "created": "2023-01-01T00:00:00Z", "content": "7|Add dark mode",
+			 "repo": {"full_name": "testuser/site", "html_url": "https://gitea.example.com/testuser/site"}}
+		]`)
+	})
+
+	activities, err := client.FetchActivity(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, activities, 2)
+	assert.Equal(t, "reopened an issue in", activities[0].Action)
+	assert.Equal(t, "reopened a pull request in", activities[1].Action)
+}
+
+func TestFetchActivityHidesEvents(t *testing.T) {
+	var client *Client
+	client = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, testFeed, client.baseURL)
+	}, WithFilter(forge.Filter{HiddenEvents: []string{"PushEvent"}}))
+
+	activities, err := client.FetchActivity(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, activities, 3)
+	assert.Equal(t, "PullRequestEvent", activities[0].Type)
+}
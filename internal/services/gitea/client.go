@@ -0,0 +1,169 @@
+// Package gitea lists repositories and activity from Gitea, or from
+// Forgejo, which shares its API. Codeberg runs Forgejo.
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+const (
+	DefaultBaseURL   = "https://codeberg.org"
+	DefaultUserAgent = "joeburgess.dev"
+)
+
+const (
+	reposPerPage = 50
+	maxRepoPages = 10
+)
+
+type Client struct {
+	username   string
+	token      string
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+
+	filter forge.Filter
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithToken authenticates requests with an access token, which shows
+// private activity the token can see.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithBaseURL points the client at another instance, such as
+// https://gitea.example.com, or a local fake. The API is expected under
+// /api/v1, and links to repositories are relative to it.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimSuffix(baseURL, "/") }
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// WithFilter sets which repositories and activity the client shows.
+func WithFilter(f forge.Filter) Option {
+	return func(c *Client) { c.filter = f }
+}
+
+func NewClient(username string, opts ...Option) *Client {
+	c := &Client{
+		username:  username,
+		baseURL:   DefaultBaseURL,
+		userAgent: DefaultUserAgent,
+		filter:    forge.DefaultFilter,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) ID() string   { return models.ForgeGitea }
+func (c *Client) Name() string { return "Gitea" }
+
+// repository is a repository as the REST API returns it, trimmed to what we
+// show.
+type repository struct {
+	Name        string    `json:"name"`
+	FullName    string    `json:"full_name"`
+	Description string    `json:"description"`
+	HTMLURL     string    `json:"html_url"`
+	Language    string    `json:"language"`
+	Topics      []string  `json:"topics"`
+	Stars       int       `json:"stars_count"`
+	Forks       int       `json:"forks_count"`
+	Fork        bool      `json:"fork"`
+	Archived    bool      `json:"archived"`
+	Template    bool      `json:"template"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (r repository) repository() models.Repository {
+	return models.Repository{
+		Forge:       models.ForgeGitea,
+		Name:        r.Name,
+		Description: r.Description,
+		URL:         r.HTMLURL,
+		Language:    r.Language,
+		Topics:      r.Topics,
+		Stars:       r.Stars,
+		Forks:       r.Forks,
+		Fork:        r.Fork,
+		Archived:    r.Archived,
+		IsTemplate:  r.Template,
+		// Gitea doesn't report pushes separately from other updates.
+		UpdatedAt: r.UpdatedAt,
+		PushedAt:  r.UpdatedAt,
+	}
+}
+
+// FetchRepositories lists the user's repositories and returns those picked
+// by the client's RepoSelection.
+func (c *Client) FetchRepositories(ctx context.Context) ([]models.Repository, error) {
+	next := fmt.Sprintf("%s/api/v1/users/%s/repos?limit=%d", c.baseURL, url.PathEscape(c.username), reposPerPage)
+
+	var repos []models.Repository
+	for page := 0; next != "" && page < maxRepoPages; page++ {
+		var batch []repository
+		var err error
+		if next, err = c.getPage(ctx, next, &batch); err != nil {
+			return nil, err
+		}
+		for _, r := range batch {
+			repos = append(repos, r.repository())
+		}
+	}
+	return c.filter.Repos.Select(repos), nil
+}
+
+func (c *Client) FetchActivity(ctx context.Context) ([]models.Activity, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/users/%s/activities/feeds?only-performed-by=true&limit=%d",
+		c.baseURL, url.PathEscape(c.username), forge.EventsPerPage)
+
+	var feed []action
+	if _, err := c.getPage(ctx, endpoint, &feed); err != nil {
+		return nil, err
+	}
+
+	activities := make([]models.Activity, 0, len(feed))
+	for _, a := range feed {
+		eventType := activityTypes[a.OpType]
+		if eventType == "" || a.Repo == nil || c.filter.Hides(eventType) {
+			continue
+		}
+		activities = append(activities, c.activity(eventType, a))
+	}
+
+	return forge.Timeline(activities), nil
+}
+
+func (c *Client) getPage(ctx context.Context, url string, v any) (string, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		header.Set("Authorization", "token "+c.token)
+	}
+	return forge.GetPage(ctx, c.httpClient, "Gitea", url, header, v)
+}
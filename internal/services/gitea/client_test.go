@@ -0,0 +1,92 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+// newTestServer starts a fake Gitea API serving handler and returns a client
+// pointed at it.
+func newTestServer(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	opts = append([]Option{WithBaseURL(srv.URL), WithHTTPClient(srv.Client())}, opts...)
+	return NewClient("testuser", opts...)
+}
+
+func TestNewClient(t *testing.T) {
+	client := NewClient("testuser")
+
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+	assert.Equal(t, models.ForgeGitea, client.ID())
+	assert.Equal(t, 10*time.Second, client.httpClient.Timeout)
+}
+
+func TestFetchRepositories(t *testing.T) {
+	var client *Client
+	client = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/users/testuser/repos", r.URL.Path)
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"name": "template", "template": true, "updated_at": "2023-01-05T00:00:00Z"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/users/testuser/repos?limit=50&page=2>; rel="next"`, client.baseURL))
+		fmt.Fprint(w, `[
+			{"name": "dotfiles", "full_name": "testuser/dotfiles", "description": "Config",
+			 "html_url": "https://codeberg.org/testuser/dotfiles", "language": "Lua", "topics": ["nvim"],
+			 "stars_count": 3, "forks_count": 1, "updated_at": "2023-01-02T00:00:00Z"},
+			{"name": "mirror", "fork": true, "updated_at": "2023-01-03T00:00:00Z"}
+		]`)
+	}, WithFilter(forge.Filter{Repos: forge.RepoSelection{SkipForks: true, SkipTemplates: true}}))
+
+	repos, err := client.FetchRepositories(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, models.Repository{
+		Forge:       models.ForgeGitea,
+		Name:        "dotfiles",
+		Description: "Config",
+		URL:         "https://codeberg.org/testuser/dotfiles",
+		Language:    "Lua",
+		Topics:      []string{"nvim"},
+		Stars:       3,
+		Forks:       1,
+		UpdatedAt:   time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		PushedAt:    time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+	}, repos[0])
+}
+
+func TestFetchRepositoriesError(t *testing.T) {
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	repos, err := client.FetchRepositories(context.Background())
+
+	assert.EqualError(t, err, "Gitea API returned status: 404 Not Found")
+	assert.Nil(t, repos)
+}
+
+func TestClientSendsToken(t *testing.T) {
+	client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token secret-token", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `[]`)
+	}, WithToken("secret-token"))
+
+	_, err := client.FetchActivity(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,144 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+// activityFromEvent maps an event to an activity, describing the specific
+// commit, pull request, issue, release or ref where the payload allows. A
+// payload we can't decode still produces the generic repository entry.
+func (c *Client) activityFromEvent(event models.GitHubEvent) models.Activity {
+	repoURL := fmt.Sprintf("%s/%s", c.webURL, event.Repo.Name)
+	activity := models.Activity{
+		Forge:     models.ForgeGitHub,
+		Type:      event.Type,
+		RepoName:  event.Repo.Name,
+		CreatedAt: event.CreatedAt,
+		URL:       repoURL,
+		Action:    models.MapActivityAction(event.Type),
+	}
+
+	if len(event.Payload) == 0 {
+		return activity
+	}
+
+	var err error
+	switch event.Type {
+	case "PushEvent":
+		var p models.PushEventPayload
+		if err = json.Unmarshal(event.Payload, &p); err == nil {
+			describePush(&activity, repoURL, p)
+		}
+	case "PullRequestEvent":
+		var p models.PullRequestEventPayload
+		if err = json.Unmarshal(event.Payload, &p); err == nil {
+			describePullRequest(&activity, p)
+		}
+	case "IssuesEvent":
+		var p models.IssuesEventPayload
+		if err = json.Unmarshal(event.Payload, &p); err == nil {
+			describeIssue(&activity, p)
+		}
+	case "ReleaseEvent":
+		var p models.ReleaseEventPayload
+		if err = json.Unmarshal(event.Payload, &p); err == nil {
+			describeRelease(&activity, p)
+		}
+	case "CreateEvent":
+		var p models.CreateEventPayload
+		if err = json.Unmarshal(event.Payload, &p); err == nil {
+			describeCreate(&activity, repoURL, p)
+		}
+	}
+	if err != nil {
+		logging.Warn("Failed to decode %s payload for %s: %v", event.Type, event.Repo.Name, err)
+	}
+
+	return activity
+}
+
+func describePush(a *models.Activity, repoURL string, p models.PushEventPayload) {
+	branch := strings.TrimPrefix(p.Ref, "refs/heads/")
+	count := p.Size
+	if count == 0 {
+		count = len(p.Commits)
+	}
+
+	switch {
+	case count == 1:
+		a.Detail = "1 commit to " + branch
+	case count > 1:
+		a.Detail = fmt.Sprintf("%d commits to %s", count, branch)
+	default:
+		a.Detail = branch
+	}
+	if len(p.Commits) > 0 {
+		// The events API lists commits oldest first.
+		if msg := forge.FirstLine(p.Commits[len(p.Commits)-1].Message); msg != "" {
+			a.Detail += ": " + msg
+		}
+	}
+
+	switch {
+	case count > 1 && p.Before != "" && p.Head != "":
+		a.DetailURL = fmt.Sprintf("%s/compare/%s...%s", repoURL, shortSHA(p.Before), shortSHA(p.Head))
+	case p.Head != "":
+		a.DetailURL = fmt.Sprintf("%s/commit/%s", repoURL, p.Head)
+	}
+}
+
+func describePullRequest(a *models.Activity, p models.PullRequestEventPayload) {
+	switch {
+	case p.Action == "closed" && p.PullRequest.Merged:
+		a.Action = "merged a pull request in"
+	case p.Action == "opened" || p.Action == "closed" || p.Action == "reopened":
+		a.Action = p.Action + " a pull request in"
+	}
+	a.Detail = fmt.Sprintf("#%d %s", p.Number, forge.Truncate(p.PullRequest.Title))
+	a.DetailURL = p.PullRequest.HTMLURL
+}
+
+func describeIssue(a *models.Activity, p models.IssuesEventPayload) {
+	if p.Action == "opened" || p.Action == "closed" || p.Action == "reopened" {
+		a.Action = p.Action + " an issue in"
+	}
+	a.Detail = fmt.Sprintf("#%d %s", p.Issue.Number, forge.Truncate(p.Issue.Title))
+	a.DetailURL = p.Issue.HTMLURL
+}
+
+func describeRelease(a *models.Activity, p models.ReleaseEventPayload) {
+	a.Action = "released"
+	a.Detail = p.Release.TagName
+	if p.Release.Name != "" && p.Release.Name != p.Release.TagName {
+		a.Detail += ": " + forge.Truncate(p.Release.Name)
+	}
+	a.DetailURL = p.Release.HTMLURL
+}
+
+func describeCreate(a *models.Activity, repoURL string, p models.CreateEventPayload) {
+	switch p.RefType {
+	case "branch":
+		a.Action = "created a branch in"
+		a.Detail = p.Ref
+		a.DetailURL = fmt.Sprintf("%s/tree/%s", repoURL, p.Ref)
+	case "tag":
+		a.Action = "created a tag in"
+		a.Detail = p.Ref
+		a.DetailURL = fmt.Sprintf("%s/releases/tag/%s", repoURL, p.Ref)
+	default:
+		a.Detail = forge.Truncate(p.Description)
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
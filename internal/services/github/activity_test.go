@@ -0,0 +1,135 @@
+package github
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+func TestActivityFromEvent(t *testing.T) {
+	tests := []struct {
+		name          string
+		eventType     string
+		payload       string
+		wantAction    string
+		wantDetail    string
+		wantDetailURL string
+	}{
+		{
+			name:          "push with one commit",
+			eventType:     "PushEvent",
+			payload:       `{"ref": "refs/heads/main", "head": "1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d", "before": "9f3c2a1e0b7d4c6f8a5e3b2d1c0f9e8d7c6b5a49", "size": 1, "commits": [{"sha": "1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d", "message": "Fix typo\n\nIn the README."}]}`,
+			wantAction:    "pushed commits to",
+			wantDetail:    "1 commit to main: Fix typo",
+			wantDetailURL: "https://github.com/testuser/repo1/commit/1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d",
+		},
+		{
+			name:          "push with several commits",
+			eventType:     "PushEvent",
+			payload:       `{"ref": "refs/heads/dev", "head": "1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d", "before": "9f3c2a1e0b7d4c6f8a5e3b2d1c0f9e8d7c6b5a49", "size": 3, "commits": [{"message": "First"}, {"message": "Second"}, {"message": "Latest"}]}`,
+			wantAction:    "pushed commits to",
+			wantDetail:    "3 commits to dev: Latest",
+			wantDetailURL: "https://github.com/testuser/repo1/compare/9f3c2a1e0b7d...1a2b3c4d5e6f",
+		},
+		{
+			name:          "push without commits",
+			eventType:     "PushEvent",
+			payload:       `{"ref": "refs/heads/main", "head": "1a2b3c4d"}`,
+			wantAction:    "pushed commits to",
+			wantDetail:    "main",
+			wantDetailURL: "https://github.com/testuser/repo1/commit/1a2b3c4d",
+		},
+		{
+			name:          "merged pull request",
+			eventType:     "PullRequestEvent",
+			payload:       `{"action": "closed", "number": 42, "pull_request": {"title": "Add dark mode", "html_url": "https://github.com/testuser/repo1/pull/42", "merged": true}}`,
+			wantAction:    "merged a pull request in",
+			wantDetail:    "#42 Add dark mode",
+			wantDetailURL: "https://github.com/testuser/repo1/pull/42",
+		},
+		{
+			name:          "opened pull request",
+			eventType:     "PullRequestEvent",
+			payload:       `{"action": "opened", "number": 7, "pull_request": {"title": "Draft", "html_url": "https://github.com/testuser/repo1/pull/7"}}`,
+			wantAction:    "opened a pull request in",
+			wantDetail:    "#7 Draft",
+			wantDetailURL: "https://github.com/testuser/repo1/pull/7",
+		},
+		{
+			name:          "closed issue",
+			eventType:     "IssuesEvent",
+			payload:       `{"action": "closed", "issue": {"number": 3, "title": "Broken link", "html_url": "https://github.com/testuser/repo1/issues/3"}}`,
+			wantAction:    "closed an issue in",
+			wantDetail:    "#3 Broken link",
+			wantDetailURL: "https://github.com/testuser/repo1/issues/3",
+		},
+		{
+			name:          "release",
+			eventType:     "ReleaseEvent",
+			payload:       `{"action": "published", "release": {"tag_name": "v1.2.0", "name": "Spring cleaning", "html_url": "https://github.com/testuser/repo1/releases/tag/v1.2.0"}}`,
+			wantAction:    "released",
+			wantDetail:    "v1.2.0: Spring cleaning",
+			wantDetailURL: "https://github.com/testuser/repo1/releases/tag/v1.2.0",
+		},
+		{
+			name:          "created branch",
+			eventType:     "CreateEvent",
+			payload:       `{"ref": "feature/forecast", "ref_type": "branch"}`,
+			wantAction:    "created a branch in",
+			wantDetail:    "feature/forecast",
+			wantDetailURL: "https://github.com/testuser/repo1/tree/feature/forecast",
+		},
+		{
+			name:       "created repository",
+			eventType:  "CreateEvent",
+			payload:    `{"ref": null, "ref_type": "repository", "description": "My new project"}`,
+			wantAction: "created",
+			wantDetail: "My new project",
+		},
+		{
+			name:       "malformed payload",
+			eventType:  "PullRequestEvent",
+			payload:    `{"number": "not a number"}`,
+			wantAction: "worked on a pull request in",
+		},
+		{
+			name:       "unhandled event",
+			eventType:  "WatchEvent",
+			payload:    `{"action": "started"}`,
+			wantAction: "starred",
+		},
+	}
+
+	client := NewClient("testuser")
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			event := models.GitHubEvent{Type: tc.eventType, Payload: json.RawMessage(tc.payload)}
+			event.Repo.Name = "testuser/repo1"
+
+			activity := client.activityFromEvent(event)
+
+			assert.Equal(t, "https://github.com/testuser/repo1", activity.URL)
+			assert.Equal(t, tc.wantAction, activity.Action)
+			assert.Equal(t, tc.wantDetail, activity.Detail)
+			assert.Equal(t, tc.wantDetailURL, activity.DetailURL)
+		})
+	}
+}
+
+func TestActivityFromEventTruncatesLongTitles(t *testing.T) {
+	title := strings.Repeat("a", 100)
+	event := models.GitHubEvent{
+		Type:    "IssuesEvent",
+		Payload: json.RawMessage(`{"action": "opened", "issue": {"number": 1, "title": "` + title + `"}}`),
+	}
+
+	activity := NewClient("testuser").activityFromEvent(event)
+
+	assert.Equal(t, forge.MaxDetailLength+len("#1 "), len([]rune(activity.Detail)))
+	assert.True(t, strings.HasSuffix(activity.Detail, "…"))
+}
@@ -1,124 +1,109 @@
 package github
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
-	"slices"
-	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
 )
 
-var ReposToExclude = []string{
-	"homebrew-formulae",
-	"excalith-start-page",
-}
-
-func shouldIncludeRepo(repoName string) bool {
-	return !slices.Contains(ReposToExclude, repoName)
-}
+const (
+	DefaultBaseURL   = "https://api.github.com"
+	DefaultWebURL    = "https://github.com"
+	DefaultUserAgent = "joeburgess.dev"
+)
 
 type Client struct {
 	username   string
+	token      string
+	baseURL    string
+	webURL     string
+	graphqlURL string
+	userAgent  string
 	httpClient *http.Client
-}
-
-func NewClient(username string) *Client {
-	return &Client{
-		username: username,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-	}
-}
 
-func (c *Client) FetchRepositories() ([]models.Repository, error) {
-	url := fmt.Sprintf("https://api.github.com/users/%s/repos?sort=updated&per_page=10", c.username)
+	filter forge.Filter
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	mu         sync.Mutex
+	rateLimits map[string]rateLimit
+	cache      map[string]cachedResponse
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status: %s", resp.Status)
-	}
+// Option configures a Client.
+type Option func(*Client)
 
-	var repos []models.Repository
-	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-		return nil, err
-	}
+// WithToken authenticates requests. Without a token GitHub allows only 60
+// requests an hour, shared by everything on our IP.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
 
-	filteredRepos := make([]models.Repository, 0)
-	for _, repo := range repos {
-		if shouldIncludeRepo(repo.Name) {
-			filteredRepos = append(filteredRepos, repo)
-		}
-	}
+// WithBaseURL points the client at another API, such as GitHub Enterprise
+// (https://ghe.example.com/api/v3) or a local fake.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimSuffix(baseURL, "/") }
+}
 
-	sort.Slice(filteredRepos, func(i, j int) bool {
-		return filteredRepos[i].UpdatedAt.After(filteredRepos[j].UpdatedAt)
-	})
+// WithWebURL sets the site used for links to repositories.
+func WithWebURL(webURL string) Option {
+	return func(c *Client) { c.webURL = strings.TrimSuffix(webURL, "/") }
+}
 
-	if len(filteredRepos) > 6 {
-		filteredRepos = filteredRepos[:6]
-	}
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
 
-	return filteredRepos, nil
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
 }
 
-func (c *Client) FetchActivity() ([]models.Activity, error) {
-	url := fmt.Sprintf("https://api.github.com/users/%s/events?per_page=10", c.username)
+// WithFilter sets which repositories and activity the client shows.
+func WithFilter(f forge.Filter) Option {
+	return func(c *Client) { c.filter = f }
+}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+func NewClient(username string, opts ...Option) *Client {
+	c := &Client{
+		username:  username,
+		baseURL:   DefaultBaseURL,
+		webURL:    DefaultWebURL,
+		userAgent: DefaultUserAgent,
+		filter:    forge.DefaultFilter,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		rateLimits: make(map[string]rateLimit),
+		cache:      make(map[string]cachedResponse),
 	}
-
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	for _, opt := range opts {
+		opt(c)
 	}
-	defer resp.Body.Close()
+	return c
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status: %s", resp.Status)
-	}
+func (c *Client) ID() string   { return models.ForgeGitHub }
+func (c *Client) Name() string { return "GitHub" }
+
+func (c *Client) FetchActivity(ctx context.Context) ([]models.Activity, error) {
+	url := fmt.Sprintf("%s/users/%s/events?per_page=%d", c.baseURL, c.username, forge.EventsPerPage)
 
 	var events models.GitHubEventResponse
-	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+	if err := c.get(ctx, url, &events); err != nil {
 		return nil, err
 	}
 
-	activities := make([]models.Activity, 0)
-
+	activities := make([]models.Activity, 0, len(events))
 	for _, event := range events {
-		activity := models.Activity{
-			Type:      event.Type,
-			RepoName:  event.Repo.Name,
-			CreatedAt: event.CreatedAt,
-			URL:       fmt.Sprintf("https://github.com/%s", event.Repo.Name),
-			Action:    models.MapActivityAction(event.Type),
+		if c.filter.Hides(event.Type) {
+			continue
 		}
-
-		activities = append(activities, activity)
-	}
-
-	if len(activities) > 6 {
-		activities = activities[:6]
+		activities = append(activities, c.activityFromEvent(event))
 	}
 
-	return activities, nil
+	return forge.Timeline(activities), nil
 }
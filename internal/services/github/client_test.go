@@ -1,46 +1,96 @@
 package github
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
 )
 
+// newTestServer starts a fake GitHub API serving handler and returns a client
+// pointed at it, along with a count of the requests it has received.
+func newTestServer(t *testing.T, handler http.HandlerFunc, opts ...Option) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	opts = append([]Option{WithBaseURL(srv.URL), WithHTTPClient(srv.Client())}, opts...)
+	return NewClient("testuser", opts...), &calls
+}
+
+// respond returns a handler that serves body with status for the given path,
+// and 404 for anything else.
+func respond(t *testing.T, path string, status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("unexpected request for %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}
+}
+
 func TestNewClient(t *testing.T) {
 	username := "testuser"
 	client := NewClient(username)
 
 	assert.Equal(t, username, client.username)
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+	assert.Equal(t, DefaultWebURL, client.webURL)
+	assert.Equal(t, DefaultUserAgent, client.userAgent)
 	assert.NotNil(t, client.httpClient)
 	assert.Equal(t, 10*time.Second, client.httpClient.Timeout)
 }
 
-func TestFetchRepositories(t *testing.T) {
-	originalExcludes := ReposToExclude
-	ReposToExclude = []string{"homebrew-formulae"}
-	defer func() { ReposToExclude = originalExcludes }()
-
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func TestNewClientOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	client := NewClient("testuser",
+		WithToken("secret-token"),
+		WithBaseURL("https://ghe.example.com/api/v3/"),
+		WithWebURL("https://ghe.example.com/"),
+		WithHTTPClient(httpClient),
+		WithUserAgent("test-agent"),
+	)
 
-	username := "testuser"
-	url := "https://api.github.com/users/testuser/repos?sort=updated&per_page=10"
+	assert.Equal(t, "secret-token", client.token)
+	assert.Equal(t, "https://ghe.example.com/api/v3", client.baseURL)
+	assert.Equal(t, "https://ghe.example.com", client.webURL)
+	assert.Same(t, httpClient, client.httpClient)
+	assert.Equal(t, "test-agent", client.userAgent)
+}
 
-	httpmock.RegisterResponder("GET", url,
-		httpmock.NewStringResponder(http.StatusOK, `[
+func TestFetchRepositories(t *testing.T) {
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/users/testuser/repos", r.URL.Path)
+		assert.Equal(t, "type=owner&sort=updated&per_page=100", r.URL.RawQuery)
+		fmt.Fprint(w, `[
 			{"name": "homebrew-formulae", "description": "Homebrew Formulae", "updated_at": "2023-01-03T00:00:00Z"},
 			{"name": "repo1", "description": "Test Repo 1", "updated_at": "2023-01-01T00:00:00Z"},
 			{"name": "repo2", "description": "Test Repo 2", "updated_at": "2023-01-02T00:00:00Z"}
-		]`))
+		]`)
+	}, WithFilter(forge.Filter{Repos: forge.RepoSelection{Exclude: []string{"homebrew-*"}}}))
 
-	client := NewClient(username)
-	repos, err := client.FetchRepositories()
+	repos, err := client.FetchRepositories(context.Background())
 
-	callCount := httpmock.GetCallCountInfo()
-	assert.Equal(t, 1, callCount["GET "+url])
+	assert.Equal(t, int32(1), calls.Load())
 
 	assert.NoError(t, err)
 	assert.NotNil(t, repos)
@@ -55,69 +105,237 @@ func TestFetchRepositories(t *testing.T) {
 }
 
 func TestFetchRepositoriesError(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
-
-	username := "testuser"
-	url := "https://api.github.com/users/testuser/repos?sort=updated&per_page=10"
-
-	httpmock.RegisterResponder("GET", url,
-		httpmock.NewStringResponder(http.StatusUnauthorized, `{"message": "Bad credentials"}`))
-
-	client := NewClient(username)
-	repos, err := client.FetchRepositories()
+	client, calls := newTestServer(t,
+		respond(t, "/users/testuser/repos", http.StatusUnauthorized, `{"message": "Bad credentials"}`))
 
-	callCount := httpmock.GetCallCountInfo()
-	assert.Equal(t, 1, callCount["GET "+url])
+	repos, err := client.FetchRepositories(context.Background())
 
+	assert.Equal(t, int32(1), calls.Load())
 	assert.Error(t, err)
 	assert.Nil(t, repos)
 }
 
 func TestFetchActivity(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
-
-	username := "testuser"
-	url := "https://api.github.com/users/testuser/events?per_page=10"
-
-	httpmock.RegisterResponder("GET", url,
-		httpmock.NewStringResponder(http.StatusOK, `[
+	client, calls := newTestServer(t,
+		respond(t, "/users/testuser/events", http.StatusOK, `[
 			{"type": "PushEvent", "repo": {"name": "testuser/repo1"}, "created_at": "2023-01-01T00:00:00Z"},
 			{"type": "WatchEvent", "repo": {"name": "testuser/repo2"}, "created_at": "2023-01-02T00:00:00Z"}
 		]`))
 
-	client := NewClient(username)
-	activities, err := client.FetchActivity()
+	activities, err := client.FetchActivity(context.Background())
 
-	callCount := httpmock.GetCallCountInfo()
-	assert.Equal(t, 1, callCount["GET "+url])
+	assert.Equal(t, int32(1), calls.Load())
 
 	assert.NoError(t, err)
 	assert.NotNil(t, activities)
 	assert.Len(t, activities, 2)
 	assert.Equal(t, "PushEvent", activities[0].Type)
 	assert.Equal(t, "pushed commits to", activities[0].Action)
+	assert.Equal(t, "https://github.com/testuser/repo1", activities[0].URL)
 	assert.Equal(t, "WatchEvent", activities[1].Type)
 	assert.Equal(t, "starred", activities[1].Action)
 }
 
-func TestFetchActivityError(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func TestFetchActivityGroupsBeforeLimiting(t *testing.T) {
+	var events []string
+	for i := range 10 {
+		events = append(events, fmt.Sprintf(`{"type": "PushEvent", "repo": {"name": "testuser/busy"}, "created_at": "2023-01-02T%02d:00:00Z"}`, 20-i))
+	}
+	events = append(events, `{"type": "WatchEvent", "repo": {"name": "someone/else"}, "created_at": "2023-01-02T08:00:00Z"}`)
+	for i := range 7 {
+		events = append(events, fmt.Sprintf(`{"type": "CreateEvent", "repo": {"name": "testuser/repo%d"}, "created_at": "2023-01-01T%02d:00:00Z"}`, i, 20-i))
+	}
 
-	username := "josephburgess"
-	url := "https://api.github.com/users/josephburgess/events?per_page=10"
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "per_page=30", r.URL.RawQuery)
+		fmt.Fprint(w, "["+strings.Join(events, ",")+"]")
+	}, WithFilter(forge.Filter{HiddenEvents: []string{"WatchEvent"}}))
 
-	httpmock.RegisterResponder("GET", url,
-		httpmock.NewStringResponder(http.StatusUnauthorized, `{"message": "Bad credentials"}`))
+	activities, err := client.FetchActivity(context.Background())
 
-	client := NewClient(username)
-	activities, err := client.FetchActivity()
+	require.NoError(t, err)
+	require.Len(t, activities, 6)
+	assert.Equal(t, "testuser/busy", activities[0].RepoName)
+	assert.Equal(t, 10, activities[0].Count)
+	for _, a := range activities[1:] {
+		assert.Equal(t, "CreateEvent", a.Type)
+	}
+}
+
+func TestFetchActivityUsesWebURL(t *testing.T) {
+	client, _ := newTestServer(t,
+		respond(t, "/users/testuser/events", http.StatusOK,
+			`[{"type": "PushEvent", "repo": {"name": "testuser/repo1"}, "created_at": "2023-01-01T00:00:00Z"}]`),
+		WithWebURL("https://ghe.example.com"))
 
-	callCount := httpmock.GetCallCountInfo()
-	assert.Equal(t, 1, callCount["GET "+url])
+	activities, err := client.FetchActivity(context.Background())
 
+	require.NoError(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, "https://ghe.example.com/testuser/repo1", activities[0].URL)
+}
+
+func TestFetchActivityError(t *testing.T) {
+	client, calls := newTestServer(t,
+		respond(t, "/users/testuser/events", http.StatusUnauthorized, `{"message": "Bad credentials"}`))
+
+	activities, err := client.FetchActivity(context.Background())
+
+	assert.Equal(t, int32(1), calls.Load())
 	assert.Error(t, err)
 	assert.Nil(t, activities)
 }
+
+func TestClientSendsHeaders(t *testing.T) {
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+		assert.Equal(t, "application/vnd.github.v3+json", r.Header.Get("Accept"))
+		fmt.Fprint(w, `[]`)
+	}, WithToken("secret-token"), WithUserAgent("test-agent"))
+
+	_, err := client.FetchActivity(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClientOmitsAuthorizationWithoutToken(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `[]`)
+	})
+
+	_, err := client.FetchActivity(context.Background())
+
+	assert.NoError(t, err)
+}
+
+func TestClientWaitsForRateLimitReset(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		fmt.Fprint(w, `[]`)
+	})
+
+	_, err := client.FetchActivity(context.Background())
+	require.NoError(t, err)
+
+	_, err = client.FetchActivity(context.Background())
+
+	var rlErr *RateLimitError
+	require.ErrorAs(t, err, &rlErr)
+	assert.Equal(t, reset, rlErr.Reset)
+	assert.Equal(t, reset, rlErr.RetryAt())
+	assert.Equal(t, int32(1), calls.Load(), "no request is made while rate limited")
+}
+
+func TestClientRateLimitResponses(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		status    int
+		headers   map[string]string
+		wantReset time.Time
+	}{
+		{
+			name:      "primary",
+			status:    http.StatusForbidden,
+			headers:   map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(reset.Unix(), 10)},
+			wantReset: reset,
+		},
+		{
+			name:      "secondary",
+			status:    http.StatusTooManyRequests,
+			headers:   map[string]string{"Retry-After": "120"},
+			wantReset: time.Now().Add(2 * time.Minute),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tc.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+			})
+
+			_, err := client.FetchActivity(context.Background())
+
+			var rlErr *RateLimitError
+			require.ErrorAs(t, err, &rlErr)
+			assert.WithinDuration(t, tc.wantReset, rlErr.Reset, 2*time.Second)
+		})
+	}
+}
+
+func TestClientForbiddenWithoutRateLimit(t *testing.T) {
+	client, _ := newTestServer(t,
+		respond(t, "/users/testuser/events", http.StatusForbidden, `{"message": "Forbidden"}`))
+
+	_, err := client.FetchActivity(context.Background())
+
+	var rlErr *RateLimitError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &rlErr))
+}
+
+func TestClientTracksRateLimitsPerResource(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	var graphqlCalls atomic.Int32
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			graphqlCalls.Add(1)
+			w.Header().Set("X-RateLimit-Resource", "graphql")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+			return
+		}
+		w.Header().Set("X-RateLimit-Resource", "core")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		fmt.Fprint(w, `[]`)
+	}, WithToken("secret-token"))
+
+	var rlErr *RateLimitError
+	_, err := client.FetchPinnedRepositories(context.Background())
+	require.ErrorAs(t, err, &rlErr)
+	assert.Equal(t, reset, rlErr.Reset)
+
+	_, err = client.FetchRepositories(context.Background())
+	assert.NoError(t, err, "GraphQL's limit doesn't block REST")
+	_, err = client.FetchActivity(context.Background())
+	assert.NoError(t, err)
+
+	_, err = client.FetchPinnedRepositories(context.Background())
+	require.ErrorAs(t, err, &rlErr)
+	assert.Equal(t, int32(1), graphqlCalls.Load(), "GraphQL waits for its own reset")
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClientConditionalRequests(t *testing.T) {
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc123"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"abc123"`)
+		fmt.Fprint(w, `[{"name": "repo1", "updated_at": "2023-01-01T00:00:00Z"}]`)
+	})
+
+	first, err := client.FetchRepositories(context.Background())
+	require.NoError(t, err)
+
+	second, err := client.FetchRepositories(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, first, second)
+	assert.Equal(t, "repo1", second[0].Name)
+}
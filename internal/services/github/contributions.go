@@ -0,0 +1,81 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// Without from and to, contributionsCollection covers the last year.
+const contributionsQuery = `query($login: String!) {
+  user(login: $login) {
+    contributionsCollection {
+      contributionCalendar {
+        totalContributions
+        weeks {
+          contributionDays { date contributionCount contributionLevel }
+        }
+      }
+    }
+  }
+}`
+
+// contributionLevels maps GitHub's ContributionLevel enum to 0-4.
+var contributionLevels = map[string]int{
+	"NONE":            0,
+	"FIRST_QUARTILE":  1,
+	"SECOND_QUARTILE": 2,
+	"THIRD_QUARTILE":  3,
+	"FOURTH_QUARTILE": 4,
+}
+
+// FetchContributions returns the user's contribution calendar for the last
+// year. It needs a token.
+func (c *Client) FetchContributions(ctx context.Context) (*models.ContributionCalendar, error) {
+	var data struct {
+		User *struct {
+			ContributionsCollection struct {
+				ContributionCalendar struct {
+					TotalContributions int
+					Weeks              []struct {
+						ContributionDays []struct {
+							Date              string
+							ContributionCount int
+							ContributionLevel string
+						}
+					}
+				}
+			}
+		}
+	}
+	if err := c.graphql(ctx, contributionsQuery, map[string]any{"login": c.username}, &data); err != nil {
+		return nil, err
+	}
+	if data.User == nil {
+		return nil, fmt.Errorf("GitHub user %q not found", c.username)
+	}
+
+	cal := data.User.ContributionsCollection.ContributionCalendar
+	result := &models.ContributionCalendar{
+		Total: cal.TotalContributions,
+		Weeks: make([]models.ContributionWeek, 0, len(cal.Weeks)),
+	}
+	for _, week := range cal.Weeks {
+		var w models.ContributionWeek
+		for _, day := range week.ContributionDays {
+			date, err := time.Parse(time.DateOnly, day.Date)
+			if err != nil {
+				return nil, fmt.Errorf("contribution date %q: %w", day.Date, err)
+			}
+			w.Days = append(w.Days, models.ContributionDay{
+				Date:  date,
+				Count: day.ContributionCount,
+				Level: contributionLevels[day.ContributionLevel],
+			})
+		}
+		result.Weeks = append(result.Weeks, w)
+	}
+	return result, nil
+}
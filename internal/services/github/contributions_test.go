@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+func TestFetchContributions(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/graphql", r.URL.Path)
+		fmt.Fprint(w, `{"data": {"user": {"contributionsCollection": {"contributionCalendar": {
+			"totalContributions": 9,
+			"weeks": [
+				{"contributionDays": [
+					{"date": "2025-03-14", "contributionCount": 0, "contributionLevel": "NONE"},
+					{"date": "2025-03-15", "contributionCount": 2, "contributionLevel": "SECOND_QUARTILE"}
+				]},
+				{"contributionDays": [
+					{"date": "2025-03-16", "contributionCount": 7, "contributionLevel": "FOURTH_QUARTILE"}
+				]}
+			]
+		}}}}}`)
+	}, WithToken("secret-token"))
+
+	cal, err := client.FetchContributions(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 9, cal.Total)
+	require.Len(t, cal.Weeks, 2)
+	assert.Equal(t, []models.ContributionDay{
+		{Date: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), Count: 0, Level: 0},
+		{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Count: 2, Level: 2},
+	}, cal.Weeks[0].Days)
+	assert.Equal(t, 4, cal.Weeks[1].Days[0].Level)
+}
+
+func TestFetchContributionsRequiresToken(t *testing.T) {
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	_, err := client.FetchContributions(context.Background())
+
+	assert.ErrorIs(t, err, ErrTokenRequired)
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestFetchContributionsUnknownUser(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": {"user": null}}`)
+	}, WithToken("secret-token"))
+
+	_, err := client.FetchContributions(context.Background())
+
+	assert.ErrorContains(t, err, `"testuser" not found`)
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrTokenRequired is returned by queries against the GraphQL API, which
+// doesn't allow anonymous access.
+var ErrTokenRequired = errors.New("GitHub GraphQL API requires a token")
+
+// WithGraphQLURL sets the GraphQL endpoint. By default it's derived from the
+// base URL: /graphql on api.github.com, /api/graphql on GitHub Enterprise.
+func WithGraphQLURL(graphqlURL string) Option {
+	return func(c *Client) { c.graphqlURL = graphqlURL }
+}
+
+func (c *Client) graphqlEndpoint() string {
+	if c.graphqlURL != "" {
+		return c.graphqlURL
+	}
+	if base, ok := strings.CutSuffix(c.baseURL, "/api/v3"); ok {
+		return base + "/api/graphql"
+	}
+	return c.baseURL + "/graphql"
+}
+
+// GraphQLError is an error reported in the body of a GraphQL response.
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return "GitHub GraphQL API: " + strings.Join(e.Messages, "; ")
+}
+
+// graphql runs query with variables and decodes the response's data into v.
+func (c *Client) graphql(ctx context.Context, query string, variables map[string]any, v any) error {
+	if c.token == "" {
+		return ErrTokenRequired
+	}
+
+	c.mu.Lock()
+	rl := c.rateLimits[resourceGraphQL]
+	c.mu.Unlock()
+	if err := rl.exhausted(); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.graphqlEndpoint(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	rl = c.updateRateLimit(resourceGraphQL, resp.Header)
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp, rl)
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding GraphQL response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		gqlErr := &GraphQLError{}
+		for _, e := range result.Errors {
+			gqlErr.Messages = append(gqlErr.Messages, e.Message)
+		}
+		return gqlErr
+	}
+	return json.Unmarshal(result.Data, v)
+}
@@ -0,0 +1,100 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// GitHub allows at most six pinned items.
+const pinnedQuery = `query($login: String!) {
+  user(login: $login) {
+    pinnedItems(first: 6, types: REPOSITORY) {
+      nodes {
+        ... on Repository {
+          name
+          description
+          url
+          stargazerCount
+          forkCount
+          isFork
+          isArchived
+          isTemplate
+          updatedAt
+          pushedAt
+          primaryLanguage { name color }
+          repositoryTopics(first: 10) { nodes { topic { name } } }
+        }
+      }
+    }
+  }
+}`
+
+type pinnedRepository struct {
+	Name            string
+	Description     string
+	URL             string
+	StargazerCount  int
+	ForkCount       int
+	IsFork          bool
+	IsArchived      bool
+	IsTemplate      bool
+	UpdatedAt       time.Time
+	PushedAt        time.Time
+	PrimaryLanguage *struct {
+		Name  string
+		Color string
+	}
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct{ Name string }
+		}
+	}
+}
+
+// FetchPinnedRepositories returns the repositories pinned to the user's
+// profile, in the order they're pinned. It needs a token.
+func (c *Client) FetchPinnedRepositories(ctx context.Context) ([]models.Repository, error) {
+	var data struct {
+		User *struct {
+			PinnedItems struct {
+				Nodes []pinnedRepository
+			}
+		}
+	}
+	if err := c.graphql(ctx, pinnedQuery, map[string]any{"login": c.username}, &data); err != nil {
+		return nil, err
+	}
+	if data.User == nil {
+		return nil, fmt.Errorf("GitHub user %q not found", c.username)
+	}
+
+	repos := make([]models.Repository, 0, len(data.User.PinnedItems.Nodes))
+	for _, node := range data.User.PinnedItems.Nodes {
+		repo := models.Repository{
+			Forge:       models.ForgeGitHub,
+			Name:        node.Name,
+			Description: node.Description,
+			URL:         node.URL,
+			Stars:       node.StargazerCount,
+			Forks:       node.ForkCount,
+			Fork:        node.IsFork,
+			Archived:    node.IsArchived,
+			IsTemplate:  node.IsTemplate,
+			UpdatedAt:   node.UpdatedAt,
+			PushedAt:    node.PushedAt,
+			Pinned:      true,
+		}
+		if lang := node.PrimaryLanguage; lang != nil {
+			repo.Language = lang.Name
+			repo.LanguageColor = lang.Color
+		}
+		for _, t := range node.RepositoryTopics.Nodes {
+			repo.Topics = append(repo.Topics, t.Topic.Name)
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
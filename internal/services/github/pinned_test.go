@@ -0,0 +1,169 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge/forgetest"
+)
+
+const pinnedResponse = `{
+  "data": {
+    "user": {
+      "pinnedItems": {
+        "nodes": [
+          {
+            "name": "breeze",
+            "description": "Weather API proxy",
+            "url": "https://github.com/testuser/breeze",
+            "stargazerCount": 12,
+            "forkCount": 2,
+            "isFork": false,
+            "isArchived": false,
+            "isTemplate": false,
+            "updatedAt": "2025-03-01T10:00:00Z",
+            "pushedAt": "2025-02-28T09:00:00Z",
+            "primaryLanguage": {"name": "Go", "color": "#00ADD8"},
+            "repositoryTopics": {"nodes": [{"topic": {"name": "weather"}}, {"topic": {"name": "api"}}]}
+          },
+          {
+            "name": "dotfiles",
+            "description": null,
+            "url": "https://github.com/testuser/dotfiles",
+            "stargazerCount": 1,
+            "forkCount": 0,
+            "isFork": false,
+            "isArchived": false,
+            "isTemplate": false,
+            "updatedAt": "2025-01-01T10:00:00Z",
+            "pushedAt": "2025-01-01T10:00:00Z",
+            "primaryLanguage": null,
+            "repositoryTopics": {"nodes": []}
+          }
+        ]
+      }
+    }
+  }
+}`
+
+func TestFetchPinnedRepositories(t *testing.T) {
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/graphql", r.URL.Path)
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.Query, "pinnedItems")
+		assert.Equal(t, "testuser", req.Variables["login"])
+
+		fmt.Fprint(w, pinnedResponse)
+	}, WithToken("secret-token"))
+
+	repos, err := client.FetchPinnedRepositories(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+	require.Len(t, repos, 2)
+
+	assert.Equal(t, "breeze", repos[0].Name)
+	assert.Equal(t, "https://github.com/testuser/breeze", repos[0].URL)
+	assert.Equal(t, 12, repos[0].Stars)
+	assert.Equal(t, "Go", repos[0].Language)
+	assert.Equal(t, "#00ADD8", repos[0].LanguageColor)
+	assert.Equal(t, []string{"weather", "api"}, repos[0].Topics)
+	assert.True(t, repos[0].Pinned)
+
+	assert.Equal(t, "dotfiles", repos[1].Name)
+	assert.Empty(t, repos[1].Language)
+	assert.Empty(t, repos[1].Topics)
+}
+
+func TestFetchPinnedRepositoriesRequiresToken(t *testing.T) {
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	_, err := client.FetchPinnedRepositories(context.Background())
+
+	assert.ErrorIs(t, err, ErrTokenRequired)
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestFetchPinnedRepositoriesGraphQLErrors(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": {"user": null}, "errors": [{"message": "Could not resolve to a User with the login of 'testuser'."}]}`)
+	}, WithToken("secret-token"))
+
+	_, err := client.FetchPinnedRepositories(context.Background())
+
+	var gqlErr *GraphQLError
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Contains(t, err.Error(), "Could not resolve")
+}
+
+func TestFetchPinnedRepositoriesRateLimited(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusForbidden)
+	}, WithToken("secret-token"))
+
+	_, err := client.FetchPinnedRepositories(context.Background())
+
+	var rlErr *RateLimitError
+	assert.ErrorAs(t, err, &rlErr)
+}
+
+func TestGraphQLEndpoint(t *testing.T) {
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{want: "https://api.github.com/graphql"},
+		{opts: []Option{WithBaseURL("https://ghe.example.com/api/v3")}, want: "https://ghe.example.com/api/graphql"},
+		{opts: []Option{WithGraphQLURL("http://localhost:9000/gql")}, want: "http://localhost:9000/gql"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, NewClient("testuser", tc.opts...).graphqlEndpoint())
+	}
+}
+
+func TestRepositoriesSourceLists(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			fmt.Fprint(w, pinnedResponse)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"name": "breeze", "html_url": "https://github.com/testuser/breeze", "updated_at": "2025-03-02T00:00:00Z"},
+			{"name": "site", "html_url": "https://github.com/testuser/site", "updated_at": "2025-03-01T00:00:00Z"},
+			{"name": "cli", "html_url": "https://github.com/testuser/cli", "updated_at": "2025-02-01T00:00:00Z"},
+			{"name": "old", "html_url": "https://github.com/testuser/old", "updated_at": "2024-02-01T00:00:00Z"}
+		]`)
+	}, WithToken("secret-token"), WithFilter(forge.Filter{Repos: forge.RepoSelection{Count: 3}}))
+
+	tests := map[string][]string{
+		ListRecent: {"breeze", "site", "cli"},
+		ListPinned: {"breeze", "dotfiles"},
+		ListBoth:   {"breeze", "dotfiles", "site"},
+	}
+
+	for list, want := range tests {
+		t.Run(list, func(t *testing.T) {
+			result, err := NewRepositoriesSource(client, list, 0).Fetch(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, want, forgetest.RepoNames(result.([]models.Repository)))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// maxRepoPages stops a runaway pagination loop. At 100 a page it still
+// covers far more repositories than anyone shows on a homepage.
+const maxRepoPages = 10
+
+// FetchRepositories lists all of the user's repositories and returns those
+// picked by the client's Filter.
+func (c *Client) FetchRepositories(ctx context.Context) ([]models.Repository, error) {
+	repos, err := c.listRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.filter.Repos.Select(repos), nil
+}
+
+// listRepositories fetches every page of the user's repositories.
+func (c *Client) listRepositories(ctx context.Context) ([]models.Repository, error) {
+	url := fmt.Sprintf("%s/users/%s/repos?type=owner&sort=updated&per_page=100", c.baseURL, c.username)
+
+	var all []models.Repository
+	for page := 0; url != "" && page < maxRepoPages; page++ {
+		var repos []models.Repository
+		next, err := c.getPage(ctx, url, &repos)
+		if err != nil {
+			return nil, err
+		}
+		for i := range repos {
+			repos[i].Forge = models.ForgeGitHub
+		}
+		all = append(all, repos...)
+		url = next
+	}
+	return all, nil
+}
@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge/forgetest"
+)
+
+func TestFetchRepositoriesFollowsPages(t *testing.T) {
+	var pages []string
+	client, calls := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("page"))
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/users/testuser/repos?page=2>; rel="next", <http://%s/users/testuser/repos?page=3>; rel="last"`, r.Host, r.Host))
+			fmt.Fprint(w, `[{"name": "repo1", "updated_at": "2023-01-01T00:00:00Z"}]`)
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/users/testuser/repos?page=3>; rel="next"`, r.Host))
+			fmt.Fprint(w, `[{"name": "repo2", "updated_at": "2023-01-02T00:00:00Z"}]`)
+		default:
+			fmt.Fprint(w, `[{"name": "repo3", "updated_at": "2023-01-03T00:00:00Z"}]`)
+		}
+	})
+
+	repos, err := client.FetchRepositories(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, []string{"", "2", "3"}, pages)
+	assert.Equal(t, []string{"repo3", "repo2", "repo1"}, forgetest.RepoNames(repos))
+}
+
+func TestFetchRepositoriesPageError(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/users/testuser/repos?page=2>; rel="next"`, r.Host))
+		fmt.Fprint(w, `[{"name": "repo1"}]`)
+	})
+
+	repos, err := client.FetchRepositories(context.Background())
+
+	assert.Error(t, err)
+	assert.Nil(t, repos)
+}
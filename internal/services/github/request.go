@@ -0,0 +1,174 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+// GitHub meters REST and GraphQL requests separately, naming the budget a
+// response counted against in its X-RateLimit-Resource header.
+const (
+	resourceCore    = "core"
+	resourceGraphQL = "graphql"
+)
+
+type rateLimit struct {
+	known     bool
+	remaining int
+	reset     time.Time
+}
+
+// cachedResponse is the last 200 response for a URL, replayed when GitHub
+// answers a conditional request with 304 Not Modified. Those don't count
+// against the rate limit.
+type cachedResponse struct {
+	etag string
+	next string
+	body []byte
+}
+
+// RateLimitError is returned when GitHub's rate limit is exhausted. The
+// client makes no further requests until Reset.
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded, resets at %s", e.Reset.Format(time.RFC3339))
+}
+
+// RetryAt tells DataUpdater when it's worth trying again.
+func (e *RateLimitError) RetryAt() time.Time {
+	return e.Reset
+}
+
+// get fetches url and decodes the JSON response into v, using conditional
+// requests and honouring the rate limit.
+func (c *Client) get(ctx context.Context, url string, v any) error {
+	_, err := c.getPage(ctx, url, v)
+	return err
+}
+
+// getPage is get for paginated endpoints. It also returns the URL of the
+// next page from the Link header, or "" on the last page.
+func (c *Client) getPage(ctx context.Context, url string, v any) (next string, err error) {
+	c.mu.Lock()
+	rl := c.rateLimits[resourceCore]
+	cached, hasCached := c.cache[url]
+	c.mu.Unlock()
+
+	if err := rl.exhausted(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	c.setHeaders(req)
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	rl = c.updateRateLimit(resourceCore, resp.Header)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if hasCached {
+			return cached.next, json.Unmarshal(cached.body, v)
+		}
+		return "", fmt.Errorf("GitHub API returned status: %s", resp.Status)
+	default:
+		return "", statusError(resp, rl)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return "", err
+	}
+
+	next = forge.NextPageURL(resp.Header.Get("Link"))
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		c.mu.Lock()
+		c.cache[url] = cachedResponse{etag: etag, next: next, body: body}
+		c.mu.Unlock()
+	}
+
+	return next, nil
+}
+
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// exhausted returns a RateLimitError if the last response we saw used up the
+// rate limit and it hasn't reset yet.
+func (rl rateLimit) exhausted() error {
+	if rl.known && rl.remaining <= 0 && time.Now().Before(rl.reset) {
+		return &RateLimitError{Reset: rl.reset}
+	}
+	return nil
+}
+
+// statusError describes an unsuccessful response, as a RateLimitError when
+// it was refused for exceeding a rate limit.
+func statusError(resp *http.Response, rl rateLimit) error {
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		// Secondary rate limits come with Retry-After, primary ones with
+		// the remaining count at zero.
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return &RateLimitError{Reset: time.Now().Add(time.Duration(secs) * time.Second)}
+		}
+		if rl.known && rl.remaining <= 0 {
+			return &RateLimitError{Reset: rl.reset}
+		}
+	}
+	return fmt.Errorf("GitHub API returned status: %s", resp.Status)
+}
+
+// updateRateLimit records the rate limit headers from a response, if present,
+// against the resource they name, or resource if they don't name one. It
+// returns the rate limit for that resource.
+func (c *Client) updateRateLimit(resource string, h http.Header) rateLimit {
+	if r := h.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return c.rateLimits[resource]
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return c.rateLimits[resource]
+	}
+
+	rl := rateLimit{known: true, remaining: remaining, reset: time.Unix(reset, 0)}
+	c.rateLimits[resource] = rl
+	return rl
+}
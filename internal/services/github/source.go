@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// Which repositories a RepositoriesSource lists: the client's selection of
+// recent ones, those pinned to the profile, or the pinned ones topped up
+// with recent ones to the selection's count.
+const (
+	ListRecent = "recent"
+	ListPinned = "pinned"
+	ListBoth   = "both"
+)
+
+// RepoLists lists the valid lists for NewRepositoriesSource.
+var RepoLists = []string{ListRecent, ListPinned, ListBoth}
+
+// RepositoriesSource exposes FetchRepositories or FetchPinnedRepositories as
+// a DataUpdater source.
+type RepositoriesSource struct {
+	client   *Client
+	list     string
+	interval time.Duration
+}
+
+func NewRepositoriesSource(client *Client, list string, interval time.Duration) *RepositoriesSource {
+	return &RepositoriesSource{client: client, list: list, interval: interval}
+}
+
+func (s *RepositoriesSource) Name() string            { return "GitHub repositories" }
+func (s *RepositoriesSource) Key() string             { return models.KeyRepositories }
+func (s *RepositoriesSource) Interval() time.Duration { return s.interval }
+
+func (s *RepositoriesSource) Fetch(ctx context.Context) (any, error) {
+	switch s.list {
+	case ListPinned:
+		repos, err := s.client.FetchPinnedRepositories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return repos, nil
+	case ListBoth:
+		pinned, err := s.client.FetchPinnedRepositories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		recent, err := s.client.FetchRepositories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return mergeRepositories(pinned, recent, s.client.filter.Repos.Count), nil
+	default:
+		repos, err := s.client.FetchRepositories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return repos, nil
+	}
+}
+
+// mergeRepositories returns pinned followed by as many of the recent
+// repositories that aren't pinned as fit in count.
+func mergeRepositories(pinned, recent []models.Repository, count int) []models.Repository {
+	repos := slices.Clone(pinned)
+	for _, repo := range recent {
+		if len(repos) >= count {
+			break
+		}
+		if !slices.ContainsFunc(pinned, func(p models.Repository) bool { return p.URL == repo.URL }) {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+// StatsSource exposes FetchStats as a DataUpdater source.
+type StatsSource struct {
+	client   *Client
+	interval time.Duration
+}
+
+func NewStatsSource(client *Client, interval time.Duration) *StatsSource {
+	return &StatsSource{client: client, interval: interval}
+}
+
+func (s *StatsSource) Name() string            { return "GitHub stats" }
+func (s *StatsSource) Key() string             { return models.KeyStats }
+func (s *StatsSource) Interval() time.Duration { return s.interval }
+
+func (s *StatsSource) Fetch(ctx context.Context) (any, error) {
+	stats, err := s.client.FetchStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ContributionsSource exposes FetchContributions as a DataUpdater source.
+type ContributionsSource struct {
+	client   *Client
+	interval time.Duration
+}
+
+func NewContributionsSource(client *Client, interval time.Duration) *ContributionsSource {
+	return &ContributionsSource{client: client, interval: interval}
+}
+
+func (s *ContributionsSource) Name() string            { return "GitHub contributions" }
+func (s *ContributionsSource) Key() string             { return models.KeyContributions }
+func (s *ContributionsSource) Interval() time.Duration { return s.interval }
+
+func (s *ContributionsSource) Fetch(ctx context.Context) (any, error) {
+	cal, err := s.client.FetchContributions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cal, nil
+}
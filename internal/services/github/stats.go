@@ -0,0 +1,145 @@
+package github
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+const (
+	// maxLanguages is how many languages the breakdown names; the rest are
+	// lumped together as "Other".
+	maxLanguages = 6
+	// languageWorkers caps concurrent /languages requests so a large
+	// account doesn't trip GitHub's secondary rate limits.
+	languageWorkers = 4
+)
+
+// FetchStats aggregates the languages of the user's own repositories, forks
+// excluded, weighted by bytes of code, along with totals for the profile.
+func (c *Client) FetchStats(ctx context.Context) (*models.GitHubStats, error) {
+	var user struct {
+		PublicRepos int `json:"public_repos"`
+		Followers   int `json:"followers"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("%s/users/%s", c.baseURL, c.username), &user); err != nil {
+		return nil, err
+	}
+
+	repos, err := c.listRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repos = slices.DeleteFunc(repos, func(r models.Repository) bool { return r.Fork })
+
+	stats := &models.GitHubStats{
+		PublicRepos: user.PublicRepos,
+		Followers:   user.Followers,
+	}
+	for _, repo := range repos {
+		stats.Stars += repo.Stars
+		stats.Forks += repo.Forks
+	}
+
+	bytes, err := c.fetchLanguages(ctx, repos)
+	if err != nil {
+		return nil, err
+	}
+	stats.Languages = languageShares(bytes)
+
+	return stats, nil
+}
+
+// fetchLanguages totals the bytes of each language across repos.
+func (c *Client) fetchLanguages(ctx context.Context, repos []models.Repository) (map[string]int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		totals   = make(map[string]int64)
+		firstErr error
+		wg       sync.WaitGroup
+		jobs     = make(chan models.Repository)
+	)
+
+	for range min(languageWorkers, len(repos)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for repo := range jobs {
+				var langs map[string]int64
+				url := fmt.Sprintf("%s/repos/%s/%s/languages", c.baseURL, c.username, repo.Name)
+				err := c.get(ctx, url, &langs)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("languages for %s: %w", repo.Name, err)
+					cancel()
+				}
+				for lang, n := range langs {
+					totals[lang] += n
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, repo := range repos {
+		select {
+		case jobs <- repo:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return totals, ctx.Err()
+}
+
+// languageShares turns byte counts into percentages, largest first, keeping
+// the top maxLanguages and folding the rest into "Other".
+func languageShares(bytes map[string]int64) []models.LanguageShare {
+	var total int64
+	shares := make([]models.LanguageShare, 0, len(bytes))
+	for name, n := range bytes {
+		if n <= 0 {
+			continue
+		}
+		total += n
+		shares = append(shares, models.LanguageShare{Name: name, Bytes: n})
+	}
+	if total == 0 {
+		return nil
+	}
+
+	slices.SortFunc(shares, func(a, b models.LanguageShare) int {
+		if c := cmp.Compare(b.Bytes, a.Bytes); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	if len(shares) > maxLanguages {
+		other := models.LanguageShare{Name: "Other"}
+		for _, s := range shares[maxLanguages-1:] {
+			other.Bytes += s.Bytes
+		}
+		shares = append(shares[:maxLanguages-1], other)
+	}
+
+	for i := range shares {
+		shares[i].Percent = math.Round(float64(shares[i].Bytes)/float64(total)*1000) / 10
+	}
+	return shares
+}
@@ -0,0 +1,81 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+func TestFetchStats(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/testuser":
+			fmt.Fprint(w, `{"login": "testuser", "public_repos": 3, "followers": 42}`)
+		case "/users/testuser/repos":
+			fmt.Fprint(w, `[
+				{"name": "site", "stargazers_count": 5, "forks_count": 1},
+				{"name": "cli", "stargazers_count": 10, "forks_count": 2},
+				{"name": "upstream", "fork": true, "stargazers_count": 1000, "forks_count": 100}
+			]`)
+		case "/repos/testuser/site/languages":
+			fmt.Fprint(w, `{"Go": 6000, "HTML": 3000, "CSS": 1000}`)
+		case "/repos/testuser/cli/languages":
+			fmt.Fprint(w, `{"Go": 10000}`)
+		default:
+			t.Errorf("unexpected request for %s", r.URL)
+			http.NotFound(w, r)
+		}
+	})
+
+	stats, err := client.FetchStats(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.PublicRepos)
+	assert.Equal(t, 42, stats.Followers)
+	assert.Equal(t, 15, stats.Stars, "forks don't count")
+	assert.Equal(t, 3, stats.Forks)
+	assert.Equal(t, []models.LanguageShare{
+		{Name: "Go", Bytes: 16000, Percent: 80},
+		{Name: "HTML", Bytes: 3000, Percent: 15},
+		{Name: "CSS", Bytes: 1000, Percent: 5},
+	}, stats.Languages)
+}
+
+func TestFetchStatsLanguageError(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/testuser":
+			fmt.Fprint(w, `{"public_repos": 1}`)
+		case "/users/testuser/repos":
+			fmt.Fprint(w, `[{"name": "site"}, {"name": "cli"}]`)
+		case "/repos/testuser/cli/languages":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, `{"Go": 1}`)
+		}
+	})
+
+	stats, err := client.FetchStats(context.Background())
+
+	assert.ErrorContains(t, err, "languages for cli")
+	assert.Nil(t, stats)
+}
+
+func TestLanguageShares(t *testing.T) {
+	shares := languageShares(map[string]int64{
+		"Go": 500, "TypeScript": 200, "HTML": 100, "CSS": 80, "Shell": 60, "Lua": 40, "Makefile": 15, "Dockerfile": 5,
+	})
+
+	require.Len(t, shares, maxLanguages)
+	assert.Equal(t, models.LanguageShare{Name: "Go", Bytes: 500, Percent: 50}, shares[0])
+	assert.Equal(t, models.LanguageShare{Name: "Other", Bytes: 60, Percent: 6}, shares[maxLanguages-1])
+
+	assert.Nil(t, languageShares(nil))
+	assert.Nil(t, languageShares(map[string]int64{"Go": 0}))
+}
@@ -0,0 +1,209 @@
+// Package gitlab lists repositories and activity from GitLab, on gitlab.com
+// or a self-managed instance.
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+const (
+	DefaultBaseURL   = "https://gitlab.com"
+	DefaultUserAgent = "joeburgess.dev"
+)
+
+const maxRepoPages = 10
+
+type Client struct {
+	username   string
+	token      string
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+
+	filter forge.Filter
+
+	// projects caches the projects events refer to by ID, since events only
+	// carry the ID and a project's name and URL rarely change.
+	mu       sync.Mutex
+	projects map[int]project
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithToken authenticates requests with a personal access token, which
+// shows private activity the token can see.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithBaseURL points the client at a self-managed instance, such as
+// https://gitlab.example.com, or a local fake.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimSuffix(baseURL, "/") }
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// WithFilter sets which repositories and activity the client shows.
+func WithFilter(f forge.Filter) Option {
+	return func(c *Client) { c.filter = f }
+}
+
+func NewClient(username string, opts ...Option) *Client {
+	c := &Client{
+		username:  username,
+		baseURL:   DefaultBaseURL,
+		userAgent: DefaultUserAgent,
+		filter:    forge.DefaultFilter,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		projects: make(map[int]project),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) ID() string   { return models.ForgeGitLab }
+func (c *Client) Name() string { return "GitLab" }
+
+// project is a project as the REST API returns it, trimmed to what we show.
+type project struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	Description       string    `json:"description"`
+	WebURL            string    `json:"web_url"`
+	Topics            []string  `json:"topics"`
+	StarCount         int       `json:"star_count"`
+	ForksCount        int       `json:"forks_count"`
+	Archived          bool      `json:"archived"`
+	ForkedFromProject *struct{} `json:"forked_from_project"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+}
+
+func (p project) repository() models.Repository {
+	return models.Repository{
+		Forge:       models.ForgeGitLab,
+		Name:        p.Name,
+		Description: p.Description,
+		URL:         p.WebURL,
+		Topics:      p.Topics,
+		Stars:       p.StarCount,
+		Forks:       p.ForksCount,
+		Fork:        p.ForkedFromProject != nil,
+		Archived:    p.Archived,
+		// GitLab doesn't say when a project was last pushed to, only when
+		// anything last happened in it.
+		UpdatedAt: p.LastActivityAt,
+		PushedAt:  p.LastActivityAt,
+	}
+}
+
+// FetchRepositories lists the user's projects and returns those picked by
+// the client's RepoSelection. GitLab doesn't report a project's language
+// without a request per project, so the cards go without.
+func (c *Client) FetchRepositories(ctx context.Context) ([]models.Repository, error) {
+	next := fmt.Sprintf("%s/api/v4/users/%s/projects?per_page=100&order_by=last_activity_at",
+		c.baseURL, url.PathEscape(c.username))
+
+	var repos []models.Repository
+	for page := 0; next != "" && page < maxRepoPages; page++ {
+		var projects []project
+		var err error
+		if next, err = c.getPage(ctx, next, &projects); err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		for _, p := range projects {
+			c.projects[p.ID] = p
+		}
+		c.mu.Unlock()
+
+		for _, p := range projects {
+			repos = append(repos, p.repository())
+		}
+	}
+	return c.filter.Repos.Select(repos), nil
+}
+
+// project returns the project with the given ID, from the cache if we've
+// seen it before.
+func (c *Client) project(ctx context.Context, id int) (project, error) {
+	c.mu.Lock()
+	p, ok := c.projects[id]
+	c.mu.Unlock()
+	if ok {
+		return p, nil
+	}
+
+	if _, err := c.getPage(ctx, fmt.Sprintf("%s/api/v4/projects/%d", c.baseURL, id), &p); err != nil {
+		return project{}, err
+	}
+
+	c.mu.Lock()
+	c.projects[id] = p
+	c.mu.Unlock()
+	return p, nil
+}
+
+func (c *Client) FetchActivity(ctx context.Context) ([]models.Activity, error) {
+	endpoint := fmt.Sprintf("%s/api/v4/users/%s/events?per_page=%d", c.baseURL, url.PathEscape(c.username), forge.EventsPerPage)
+
+	var events []event
+	if _, err := c.getPage(ctx, endpoint, &events); err != nil {
+		return nil, err
+	}
+
+	activities := make([]models.Activity, 0, len(events))
+	for _, e := range events {
+		eventType := e.activityType()
+		if eventType == "" || c.filter.Hides(eventType) {
+			continue
+		}
+
+		p, err := c.project(ctx, e.ProjectID)
+		var status *forge.StatusError
+		if errors.As(err, &status) && status.StatusCode == http.StatusNotFound {
+			// Deleted, or private and we have no token to see it.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("project %d: %w", e.ProjectID, err)
+		}
+
+		activities = append(activities, e.activity(eventType, p))
+	}
+
+	return forge.Timeline(activities), nil
+}
+
+func (c *Client) getPage(ctx context.Context, url string, v any) (string, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		header.Set("PRIVATE-TOKEN", c.token)
+	}
+	return forge.GetPage(ctx, c.httpClient, "GitLab", url, header, v)
+}
@@ -0,0 +1,98 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+// newTestServer starts a fake GitLab API serving handler and returns a client
+// pointed at it, along with a count of the requests it has received.
+func newTestServer(t *testing.T, handler http.HandlerFunc, opts ...Option) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	opts = append([]Option{WithBaseURL(srv.URL), WithHTTPClient(srv.Client())}, opts...)
+	return NewClient("testuser", opts...), &calls
+}
+
+func TestNewClient(t *testing.T) {
+	client := NewClient("testuser")
+
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+	assert.Equal(t, models.ForgeGitLab, client.ID())
+	assert.Equal(t, "GitLab", client.Name())
+	assert.Equal(t, 10*time.Second, client.httpClient.Timeout)
+}
+
+func TestFetchRepositories(t *testing.T) {
+	var srvURL string
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/users/testuser/projects", r.URL.Path)
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"id": 3, "name": "old", "archived": true, "last_activity_at": "2023-01-01T00:00:00Z"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/users/testuser/projects?page=2>; rel="next"`, srvURL))
+		fmt.Fprint(w, `[
+			{"id": 1, "name": "site", "description": "My site", "web_url": "https://gitlab.com/testuser/site",
+			 "topics": ["go"], "star_count": 4, "forks_count": 1, "last_activity_at": "2023-01-03T00:00:00Z"},
+			{"id": 2, "name": "upstream", "forked_from_project": {"id": 9}, "last_activity_at": "2023-01-04T00:00:00Z"}
+		]`)
+	}, WithFilter(forge.Filter{Repos: forge.RepoSelection{SkipForks: true, SkipArchived: true}}))
+	srvURL = client.baseURL
+
+	repos, err := client.FetchRepositories(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, models.Repository{
+		Forge:       models.ForgeGitLab,
+		Name:        "site",
+		Description: "My site",
+		URL:         "https://gitlab.com/testuser/site",
+		Topics:      []string{"go"},
+		Stars:       4,
+		Forks:       1,
+		UpdatedAt:   time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+		PushedAt:    time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC),
+	}, repos[0])
+}
+
+func TestFetchRepositoriesError(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	repos, err := client.FetchRepositories(context.Background())
+
+	assert.EqualError(t, err, "GitLab API returned status: 500 Internal Server Error")
+	assert.Nil(t, repos)
+}
+
+func TestClientSendsHeaders(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret-token", r.Header.Get("PRIVATE-TOKEN"))
+		assert.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+		fmt.Fprint(w, `[]`)
+	}, WithToken("secret-token"), WithUserAgent("test-agent"))
+
+	_, err := client.FetchRepositories(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,123 @@
+package gitlab
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+// event is an entry from the events API, trimmed to the fields we display.
+// See https://docs.gitlab.com/api/events/.
+type event struct {
+	ProjectID   int       `json:"project_id"`
+	ActionName  string    `json:"action_name"`
+	TargetType  string    `json:"target_type"`
+	TargetIID   int       `json:"target_iid"`
+	TargetTitle string    `json:"target_title"`
+	CreatedAt   time.Time `json:"created_at"`
+	PushData    *struct {
+		CommitCount int    `json:"commit_count"`
+		Action      string `json:"action"`
+		RefType     string `json:"ref_type"`
+		CommitFrom  string `json:"commit_from"`
+		CommitTo    string `json:"commit_to"`
+		Ref         string `json:"ref"`
+		CommitTitle string `json:"commit_title"`
+	} `json:"push_data"`
+}
+
+// activityType names the event as GitHub would, or returns "" for events we
+// don't show, such as comments, deleted branches and joining a project.
+func (e event) activityType() string {
+	switch {
+	case e.PushData != nil:
+		switch {
+		case e.PushData.Action == "removed":
+			return ""
+		case e.PushData.Action == "created" && (e.PushData.RefType == "tag" || e.PushData.CommitCount == 0):
+			return "CreateEvent"
+		default:
+			return "PushEvent"
+		}
+	case e.TargetType == "MergeRequest":
+		return "PullRequestEvent"
+	case e.TargetType == "Issue":
+		return "IssuesEvent"
+	case e.TargetType == "" && e.ActionName == "created":
+		return "CreateEvent"
+	default:
+		return ""
+	}
+}
+
+func (e event) activity(eventType string, p project) models.Activity {
+	a := models.Activity{
+		Forge:     models.ForgeGitLab,
+		Type:      eventType,
+		RepoName:  p.PathWithNamespace,
+		CreatedAt: e.CreatedAt,
+		URL:       p.WebURL,
+		Action:    models.MapActivityAction(eventType),
+	}
+
+	switch {
+	case e.PushData != nil && eventType == "PushEvent":
+		describePush(&a, p.WebURL, e)
+	case e.PushData != nil:
+		describeRef(&a, p.WebURL, e)
+	case e.TargetType == "MergeRequest":
+		switch e.ActionName {
+		case "accepted", "merged":
+			a.Action = "merged a merge request in"
+		case "opened", "closed", "reopened":
+			a.Action = e.ActionName + " a merge request in"
+		}
+		a.Detail = fmt.Sprintf("!%d %s", e.TargetIID, forge.Truncate(e.TargetTitle))
+		a.DetailURL = fmt.Sprintf("%s/-/merge_requests/%d", p.WebURL, e.TargetIID)
+	case e.TargetType == "Issue":
+		switch e.ActionName {
+		case "opened", "closed", "reopened":
+			a.Action = e.ActionName + " an issue in"
+		}
+		a.Detail = fmt.Sprintf("#%d %s", e.TargetIID, forge.Truncate(e.TargetTitle))
+		a.DetailURL = fmt.Sprintf("%s/-/issues/%d", p.WebURL, e.TargetIID)
+	}
+	return a
+}
+
+func describePush(a *models.Activity, webURL string, e event) {
+	pd := e.PushData
+	switch {
+	case pd.CommitCount == 1:
+		a.Detail = "1 commit to " + pd.Ref
+	case pd.CommitCount > 1:
+		a.Detail = fmt.Sprintf("%d commits to %s", pd.CommitCount, pd.Ref)
+	default:
+		a.Detail = pd.Ref
+	}
+	if msg := forge.FirstLine(pd.CommitTitle); msg != "" {
+		a.Detail += ": " + msg
+	}
+
+	switch {
+	// A push that creates a branch comes from the all-zero SHA.
+	case pd.CommitCount > 1 && strings.Trim(pd.CommitFrom, "0") != "" && pd.CommitTo != "":
+		a.DetailURL = fmt.Sprintf("%s/-/compare/%s...%s", webURL, pd.CommitFrom, pd.CommitTo)
+	case pd.CommitTo != "":
+		a.DetailURL = fmt.Sprintf("%s/-/commit/%s", webURL, pd.CommitTo)
+	}
+}
+
+func describeRef(a *models.Activity, webURL string, e event) {
+	a.Detail = e.PushData.Ref
+	if e.PushData.RefType == "tag" {
+		a.Action = "created a tag in"
+		a.DetailURL = fmt.Sprintf("%s/-/tags/%s", webURL, e.PushData.Ref)
+	} else {
+		a.Action = "created a branch in"
+		a.DetailURL = fmt.Sprintf("%s/-/tree/%s", webURL, e.PushData.Ref)
+	}
+}
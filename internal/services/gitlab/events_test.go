@@ -0,0 +1,106 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+const testEvents = `[
+	{"project_id": 1, "action_name": "pushed to", "created_at": "2023-01-05T00:00:00Z",
+	 "push_data": {"commit_count": 2, "action": "pushed", "ref_type": "branch", "ref": "main",
+	               "commit_from": "aaa111", "commit_to": "bbb222", "commit_title": "Fix the build\n\nDetails"}},
+	{"project_id": 1, "action_name": "accepted", "target_type": "MergeRequest", "target_iid": 7,
+	 "target_title": "Add dark mode", "created_at": "2023-01-04T00:00:00Z"},
+	{"project_id": 1, "action_name": "commented on", "target_type": "Note", "created_at": "2023-01-04T00:00:00Z"},
+	{"project_id": 2, "action_name": "opened", "target_type": "Issue", "target_iid": 3,
+	 "target_title": "Crash on start", "created_at": "2023-01-03T00:00:00Z"},
+	{"project_id": 2, "action_name": "pushed new", "created_at": "2023-01-02T00:00:00Z",
+	 "push_data": {"commit_count": 0, "action": "created", "ref_type": "tag", "ref": "v1.0.0"}},
+	{"project_id": 404, "action_name": "opened", "target_type": "Issue", "target_iid": 1, "created_at": "2023-01-01T00:00:00Z"}
+]`
+
+func eventsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/users/testuser/events":
+			fmt.Fprint(w, testEvents)
+		case "/api/v4/projects/1":
+			fmt.Fprint(w, `{"id": 1, "path_with_namespace": "testuser/site", "web_url": "https://gitlab.com/testuser/site"}`)
+		case "/api/v4/projects/2":
+			fmt.Fprint(w, `{"id": 2, "path_with_namespace": "group/tool", "web_url": "https://gitlab.com/group/tool"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestFetchActivity(t *testing.T) {
+	client, calls := newTestServer(t, eventsHandler())
+
+	activities, err := client.FetchActivity(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, activities, 4, "comments and projects we can't see are skipped")
+	assert.EqualValues(t, 4, calls.Load(), "each project is looked up once")
+
+	push := activities[0]
+	assert.Equal(t, models.ForgeGitLab, push.Forge)
+	assert.Equal(t, "PushEvent", push.Type)
+	assert.Equal(t, "testuser/site", push.RepoName)
+	assert.Equal(t, "https://gitlab.com/testuser/site", push.URL)
+	assert.Equal(t, "2 commits to main: Fix the build", push.Detail)
+	assert.Equal(t, "https://gitlab.com/testuser/site/-/compare/aaa111...bbb222", push.DetailURL)
+
+	mr := activities[1]
+	assert.Equal(t, "PullRequestEvent", mr.Type)
+	assert.Equal(t, "merged a merge request in", mr.Action)
+	assert.Equal(t, "!7 Add dark mode", mr.Detail)
+	assert.Equal(t, "https://gitlab.com/testuser/site/-/merge_requests/7", mr.DetailURL)
+
+	issue := activities[2]
+	assert.Equal(t, "IssuesEvent", issue.Type)
+	assert.Equal(t, "opened an issue in", issue.Action)
+	assert.Equal(t, "https://gitlab.com/group/tool/-/issues/3", issue.DetailURL)
+
+	tag := activities[3]
+	assert.Equal(t, "CreateEvent", tag.Type)
+	assert.Equal(t, "created a tag in", tag.Action)
+	assert.Equal(t, "v1.0.0", tag.Detail)
+	assert.Equal(t, time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), tag.CreatedAt)
+}
+
+func TestFetchActivityHidesEvents(t *testing.T) {
+	client, _ := newTestServer(t, eventsHandler(), WithFilter(forge.Filter{HiddenEvents: []string{"PushEvent", "CreateEvent"}}))
+
+	activities, err := client.FetchActivity(context.Background())
+
+	require.NoError(t, err)
+	for _, a := range activities {
+		assert.NotContains(t, []string{"PushEvent", "CreateEvent"}, a.Type)
+	}
+	assert.Len(t, activities, 2)
+}
+
+func TestFetchActivityProjectError(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/users/testuser/events" {
+			fmt.Fprint(w, testEvents)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	activities, err := client.FetchActivity(context.Background())
+
+	assert.ErrorContains(t, err, "project 1: GitLab API returned status: 502 Bad Gateway")
+	assert.Nil(t, activities)
+}
@@ -0,0 +1,69 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+const (
+	// DefaultBreezeURL is a breeze instance running alongside the site.
+	DefaultBreezeURL  = "http://localhost:8080"
+	DefaultBreezeLink = "https://github.com/josephburgess/breeze"
+)
+
+// Breeze fetches the weather from a breeze instance, which looks places up
+// and calls OpenWeatherMap with its own key. The breeze key is sent as a
+// bearer token so it stays out of URLs and proxy logs. See
+// https://github.com/josephburgess/breeze.
+type Breeze struct {
+	apiKey     string
+	baseURL    string
+	link       string
+	httpClient *http.Client
+}
+
+func NewBreeze(apiURL, apiKey string, httpClient *http.Client) (*Breeze, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("breeze: %w", ErrNoAPIKey)
+	}
+	logging.AddSecrets(apiKey)
+	return &Breeze{
+		apiKey:     apiKey,
+		baseURL:    baseURL(apiURL, DefaultBreezeURL),
+		link:       DefaultBreezeLink,
+		httpClient: httpClient,
+	}, nil
+}
+
+func (b *Breeze) Name() string { return "Breeze API" }
+func (b *Breeze) Link() string { return b.link }
+
+// breezeResponse is the body of breeze's /api/weather/{location}.
+type breezeResponse struct {
+	Weather *oneCall `json:"weather"`
+}
+
+// FetchWeather fetches the current weather and forecast for location, by
+// name when it has one and by coordinates otherwise.
+func (b *Breeze) FetchWeather(ctx context.Context, location models.Location) (*models.WeatherData, error) {
+	requestURL := fmt.Sprintf("%s/api/weather/%s?units=%s",
+		b.baseURL,
+		url.QueryEscape(location.Query()),
+		"metric",
+	)
+	header := http.Header{"Authorization": {"Bearer " + b.apiKey}}
+
+	var result breezeResponse
+	if err := getJSON(ctx, b.httpClient, "breeze", requestURL, header, &result); err != nil {
+		return nil, err
+	}
+	if result.Weather == nil {
+		return nil, ErrNoWeather
+	}
+	return result.Weather.weatherData(location.String())
+}
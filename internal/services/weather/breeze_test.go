@@ -0,0 +1,131 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging/logtest"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// newTestBreeze returns a Breeze pointed at a local server that answers
+// every request with status and body, and the last request it saw.
+func newTestBreeze(t *testing.T, status int, body string) (*Breeze, **http.Request) {
+	t.Helper()
+	var requested *http.Request
+	url := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requested = r
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+
+	b, err := NewBreeze(url, "test_api_key", http.DefaultClient)
+	require.NoError(t, err)
+	return b, &requested
+}
+
+func TestBreezeFetchWeather(t *testing.T) {
+	b, requested := newTestBreeze(t, http.StatusOK, `{"weather": `+londonOneCall+`}`)
+
+	weatherData, err := b.FetchWeather(context.Background(), models.Location{Name: "London"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/api/weather/London?units=metric", (*requested).URL.RequestURI())
+	assert.Equal(t, "Bearer test_api_key", (*requested).Header.Get("Authorization"))
+	assert.Equal(t, "London", weatherData.Location)
+	assertLondon(t, weatherData)
+}
+
+func TestBreezeFetchWeatherByCoordinates(t *testing.T) {
+	b, requested := newTestBreeze(t, http.StatusOK, `{"weather": `+londonOneCall+`}`)
+
+	location := models.Location{Coordinates: &models.Coordinates{Lat: 51.5, Lon: -0.1}}
+	weatherData, err := b.FetchWeather(context.Background(), location)
+
+	require.NoError(t, err)
+	assert.Equal(t, "/api/weather/51.5%2C-0.1?units=metric", (*requested).URL.RequestURI())
+	assert.Equal(t, "51.5°N, 0.1°W", weatherData.Location)
+}
+
+func TestBreezeFetchWeatherAPIError(t *testing.T) {
+	b, _ := newTestBreeze(t, http.StatusBadRequest, `{"error": "Location not found"}`)
+
+	weatherData, err := b.FetchWeather(context.Background(), models.Location{Name: "InvalidLocation"})
+
+	assert.ErrorContains(t, err, "breeze API returned status: 400 Bad Request")
+	assert.Nil(t, weatherData)
+}
+
+func TestBreezeKeyStaysOutOfLogs(t *testing.T) {
+	const key = "breeze_secret_key"
+
+	t.Run("error response echoing the key", func(t *testing.T) {
+		logs := logtest.Capture(t)
+		url := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"error": "invalid key %s"}`, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		})
+		b, err := NewBreeze(url, key, http.DefaultClient)
+		require.NoError(t, err)
+
+		_, err = b.FetchWeather(context.Background(), models.Location{Name: "London"})
+
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), key)
+		assert.Contains(t, logs.String(), "invalid key REDACTED")
+		assert.NotContains(t, logs.String(), key)
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		logs := logtest.Capture(t)
+		b, err := NewBreeze(closedServerURL(), key, http.DefaultClient)
+		require.NoError(t, err)
+
+		_, err = b.FetchWeather(context.Background(), models.Location{Name: "London"})
+
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), key)
+		assert.NotEmpty(t, logs.String())
+		assert.NotContains(t, logs.String(), key)
+	})
+}
+
+func TestBreezeFetchWeatherMalformedResponses(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want error
+	}{
+		"not JSON":        {`<html>`, ErrInvalidResponse},
+		"wrong types":     {`{"weather": {"current": {"temp": "warm"}}}`, ErrInvalidResponse},
+		"no weather":      {`{"data": "not what we expect"}`, ErrNoWeather},
+		"no current":      {`{"weather": {"daily": []}}`, ErrNoCurrent},
+		"no temperature":  {`{"weather": {"current": {"weather": [{"main": "Rain"}]}}}`, ErrNoTemperature},
+		"no condition":    {`{"weather": {"current": {"temp": 1, "weather": []}}}`, ErrNoCondition},
+		"no daily":        {`{"weather": {"current": {"temp": 1, "weather": [{"main": "Rain"}]}}}`, ErrNoDaily},
+		"empty condition": {`{"weather": {"current": {"temp": 1, "weather": [{}]}, "daily": [{}]}}`, nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, _ := newTestBreeze(t, http.StatusOK, tc.body)
+
+			weatherData, err := b.FetchWeather(context.Background(), models.Location{Name: "London"})
+
+			if tc.want == nil {
+				require.NoError(t, err)
+				assert.Equal(t, "Unknown", weatherData.Condition)
+				assert.Equal(t, models.WeatherClearDay, weatherData.Code)
+				return
+			}
+			assert.ErrorIs(t, err, tc.want)
+			assert.ErrorIs(t, err, ErrInvalidResponse)
+			assert.Nil(t, weatherData)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package weather
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// Locations holds the location the weather is fetched for. It starts at the
+// configured default, and updates are saved to a JSON file so they survive
+// restarts.
+type Locations struct {
+	mu      sync.RWMutex
+	path    string
+	current models.Location
+}
+
+// NewLocations restores the last saved location from path, falling back to
+// def when nothing has been saved yet. An empty path keeps updates in memory.
+func NewLocations(path string, def models.Location) (*Locations, error) {
+	l := &Locations{path: path, current: def}
+	if path == "" {
+		return l, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return l, err
+	}
+
+	var saved models.Location
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return l, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	if !saved.IsZero() {
+		l.current = saved
+	}
+	return l, nil
+}
+
+func (l *Locations) Current() models.Location {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// Set coarsens loc's coordinates, saves it and makes it current. It reports
+// whether the place changed, so callers only refresh the weather when it
+// did.
+func (l *Locations) Set(loc models.Location) (bool, error) {
+	if loc.Coordinates != nil {
+		coarse := loc.Coordinates.Coarsen()
+		loc.Coordinates = &coarse
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	changed := !loc.Same(l.current)
+	if err := l.save(loc); err != nil {
+		return false, err
+	}
+	l.current = loc
+	return changed, nil
+}
+
+func (l *Locations) save(loc models.Location) error {
+	if l.path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(loc)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(l.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(l.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
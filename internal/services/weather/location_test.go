@@ -0,0 +1,70 @@
+package weather
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+func TestLocationsDefault(t *testing.T) {
+	def := models.Location{Name: "London, GB"}
+
+	locations, err := NewLocations(filepath.Join(t.TempDir(), "location.json"), def)
+
+	require.NoError(t, err)
+	assert.Equal(t, def, locations.Current())
+}
+
+func TestLocationsSetCoarsensAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "location.json")
+	locations, err := NewLocations(path, models.Location{Name: "London, GB"})
+	require.NoError(t, err)
+
+	updated := time.Date(2025, 5, 1, 9, 30, 0, 0, time.UTC)
+	changed, err := locations.Set(models.Location{
+		Coordinates: &models.Coordinates{Lat: 38.72234, Lon: -9.13934},
+		UpdatedAt:   updated,
+	})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	want := models.Location{Coordinates: &models.Coordinates{Lat: 38.7, Lon: -9.1}, UpdatedAt: updated}
+	assert.Equal(t, want, locations.Current())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "38.72", "precise coordinates are never written")
+
+	restarted, err := NewLocations(path, models.Location{Name: "London, GB"})
+	require.NoError(t, err)
+	assert.Equal(t, want, restarted.Current())
+}
+
+func TestLocationsSetUnchanged(t *testing.T) {
+	locations, err := NewLocations("", models.Location{})
+	require.NoError(t, err)
+
+	changed, err := locations.Set(models.Location{Coordinates: &models.Coordinates{Lat: 51.501, Lon: -0.142}})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = locations.Set(models.Location{Coordinates: &models.Coordinates{Lat: 51.509, Lon: -0.128}})
+	require.NoError(t, err)
+	assert.False(t, changed, "moving within the same coarse cell isn't a change")
+}
+
+func TestLocationsRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "location.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	locations, err := NewLocations(path, models.Location{Name: "London, GB"})
+
+	assert.Error(t, err)
+	assert.Equal(t, "London, GB", locations.Current().Name)
+}
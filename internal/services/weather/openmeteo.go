@@ -0,0 +1,195 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+const (
+	DefaultOpenMeteoURL          = "https://api.open-meteo.com"
+	DefaultOpenMeteoGeocodingURL = "https://geocoding-api.open-meteo.com"
+)
+
+// OpenMeteo fetches the weather from Open-Meteo, which needs no key for
+// non-commercial use. See https://open-meteo.com/en/docs.
+type OpenMeteo struct {
+	baseURL      string
+	geocodingURL string
+	httpClient   *http.Client
+	geocoder     geocoder
+}
+
+func NewOpenMeteo(apiURL, geocodingURL string, httpClient *http.Client) *OpenMeteo {
+	o := &OpenMeteo{
+		baseURL:      baseURL(apiURL, DefaultOpenMeteoURL),
+		geocodingURL: baseURL(geocodingURL, DefaultOpenMeteoGeocodingURL),
+		httpClient:   httpClient,
+	}
+	o.geocoder.search = o.search
+	return o
+}
+
+func (o *OpenMeteo) Name() string { return "Open-Meteo" }
+func (o *OpenMeteo) Link() string { return "https://open-meteo.com" }
+
+// forecastDays is today plus the days after it that the widget can show.
+const forecastDays = 4
+
+type openMeteoResponse struct {
+	UTCOffsetSeconds int `json:"utc_offset_seconds"`
+	Current          *struct {
+		Temperature   *float64 `json:"temperature_2m"`
+		FeelsLike     float64  `json:"apparent_temperature"`
+		Humidity      int      `json:"relative_humidity_2m"`
+		WindSpeed     float64  `json:"wind_speed_10m"`
+		WindDirection int      `json:"wind_direction_10m"`
+		WeatherCode   *int     `json:"weather_code"`
+		IsDay         int      `json:"is_day"`
+	} `json:"current"`
+	// Daily holds one entry per day in each list, starting today.
+	Daily struct {
+		Time        []int64   `json:"time"`
+		WeatherCode []int     `json:"weather_code"`
+		Max         []float64 `json:"temperature_2m_max"`
+		Min         []float64 `json:"temperature_2m_min"`
+		Sunrise     []int64   `json:"sunrise"`
+		Sunset      []int64   `json:"sunset"`
+	} `json:"daily"`
+}
+
+func (o *OpenMeteo) FetchWeather(ctx context.Context, location models.Location) (*models.WeatherData, error) {
+	c, err := o.geocoder.coordinates(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{
+		"latitude":        {fmt.Sprint(c.Lat)},
+		"longitude":       {fmt.Sprint(c.Lon)},
+		"current":         {"temperature_2m,apparent_temperature,relative_humidity_2m,wind_speed_10m,wind_direction_10m,weather_code,is_day"},
+		"daily":           {"weather_code,temperature_2m_max,temperature_2m_min,sunrise,sunset"},
+		"wind_speed_unit": {"ms"},
+		"timezone":        {"auto"},
+		"timeformat":      {"unixtime"},
+		"forecast_days":   {fmt.Sprint(forecastDays)},
+	}
+	var result openMeteoResponse
+	if err := getJSON(ctx, o.httpClient, "Open-Meteo", o.baseURL+"/v1/forecast?"+q.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return result.weatherData(location.String())
+}
+
+func (r openMeteoResponse) weatherData(location string) (*models.WeatherData, error) {
+	d := r.Daily
+	days := min(len(d.Time), len(d.WeatherCode), len(d.Max), len(d.Min), len(d.Sunrise), len(d.Sunset))
+	switch {
+	case r.Current == nil:
+		return nil, ErrNoCurrent
+	case r.Current.Temperature == nil:
+		return nil, ErrNoTemperature
+	case r.Current.WeatherCode == nil:
+		return nil, ErrNoCondition
+	case days == 0:
+		return nil, ErrNoDaily
+	}
+
+	zone := time.FixedZone("", r.UTCOffsetSeconds)
+	cond := wmoCondition(*r.Current.WeatherCode, r.Current.IsDay == 1)
+
+	forecast := make([]models.DailyForecast, 0, days-1)
+	for i := 1; i < days; i++ {
+		c := wmoCondition(d.WeatherCode[i], true)
+		forecast = append(forecast, models.DailyForecast{
+			// Days start at local midnight; the forecast is dated midday.
+			Date:      unixTime(d.Time[i], zone).Add(12 * time.Hour),
+			High:      d.Max[i],
+			Low:       d.Min[i],
+			Condition: c.Main,
+			Code:      c.code(),
+		})
+	}
+
+	return &models.WeatherData{
+		Location:      location,
+		Temperature:   *r.Current.Temperature,
+		FeelsLike:     r.Current.FeelsLike,
+		High:          d.Max[0],
+		Low:           d.Min[0],
+		Humidity:      r.Current.Humidity,
+		WindSpeed:     r.Current.WindSpeed,
+		WindDirection: r.Current.WindDirection,
+		Sunrise:       unixTime(d.Sunrise[0], zone),
+		Sunset:        unixTime(d.Sunset[0], zone),
+		Condition:     cond.Main,
+		Code:          cond.code(),
+		Forecast:      forecast,
+		LastUpdated:   time.Now(),
+	}, nil
+}
+
+// wmoCondition maps a WMO weather interpretation code, as Open-Meteo
+// reports them, onto OpenWeatherMap's condition groups and icons so every
+// provider looks the same in the widget.
+func wmoCondition(code int, day bool) condition {
+	var c condition
+	switch {
+	case code == 0:
+		c = condition{"Clear", "01"}
+	case code == 1 || code == 2:
+		c = condition{"Clouds", "02"}
+	case code == 3:
+		c = condition{"Clouds", "04"}
+	case code == 45 || code == 48:
+		c = condition{"Fog", "50"}
+	case code >= 51 && code <= 57:
+		c = condition{"Drizzle", "09"}
+	case code >= 61 && code <= 67:
+		c = condition{"Rain", "10"}
+	case code >= 71 && code <= 77, code == 85 || code == 86:
+		c = condition{"Snow", "13"}
+	case code >= 80 && code <= 82:
+		c = condition{"Rain", "09"}
+	case code >= 95 && code <= 99:
+		c = condition{"Thunderstorm", "11"}
+	default:
+		return condition{}.withDefaults()
+	}
+
+	if day {
+		c.Icon += "d"
+	} else {
+		c.Icon += "n"
+	}
+	return c
+}
+
+// search looks a place up with Open-Meteo's geocoding API. It only matches
+// place names, so for "London, GB" it searches for London and picks the
+// first result in GB.
+func (o *OpenMeteo) search(ctx context.Context, name string) (models.Coordinates, error) {
+	place, country := splitPlace(name)
+	q := url.Values{"name": {place}, "count": {"10"}}
+
+	var result struct {
+		Results []struct {
+			Latitude    float64 `json:"latitude"`
+			Longitude   float64 `json:"longitude"`
+			CountryCode string  `json:"country_code"`
+		} `json:"results"`
+	}
+	if err := getJSON(ctx, o.httpClient, "Open-Meteo", o.geocodingURL+"/v1/search?"+q.Encode(), nil, &result); err != nil {
+		return models.Coordinates{}, err
+	}
+	for _, r := range result.Results {
+		if country == "" || r.CountryCode == country {
+			return models.Coordinates{Lat: r.Latitude, Lon: r.Longitude}, nil
+		}
+	}
+	return models.Coordinates{}, ErrLocationNotFound
+}
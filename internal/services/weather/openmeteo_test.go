@@ -0,0 +1,165 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// londonForecast is Open-Meteo's forecast for the same day as
+// londonOneCall.
+const londonForecast = `{
+	"utc_offset_seconds": 3600,
+	"current": {
+		"time": 1714050000,
+		"temperature_2m": 15.5,
+		"apparent_temperature": 14.2,
+		"relative_humidity_2m": 72,
+		"wind_speed_10m": 4.1,
+		"wind_direction_10m": 230,
+		"weather_code": 0,
+		"is_day": 1
+	},
+	"daily": {
+		"time": [1713999600, 1714086000, 1714172400],
+		"weather_code": [0, 61, 3],
+		"temperature_2m_max": [17.0, 14.6, 12.3],
+		"temperature_2m_min": [9.5, 8.1, 7.0],
+		"sunrise": [1714020720, 1714107000, 1714193280],
+		"sunset": [1714072320, 1714158840, 1714245360]
+	}
+}`
+
+const londonSearch = `{"results": [
+	{"name": "London", "latitude": 42.98339, "longitude": -81.23304, "country_code": "CA"},
+	{"name": "London", "latitude": 51.50853, "longitude": -0.12574, "country_code": "GB"}
+]}`
+
+func TestOpenMeteoFetchWeather(t *testing.T) {
+	var searched int
+	geocodingURL := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		searched++
+		assert.Equal(t, "/v1/search", r.URL.Path)
+		assert.Equal(t, "London", r.URL.Query().Get("name"))
+		fmt.Fprint(w, londonSearch)
+	})
+	apiURL := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "/v1/forecast", r.URL.Path)
+		assert.Equal(t, "51.50853", q.Get("latitude"), "the result in GB is picked")
+		assert.Equal(t, "-0.12574", q.Get("longitude"))
+		assert.Equal(t, "ms", q.Get("wind_speed_unit"))
+		assert.Equal(t, "unixtime", q.Get("timeformat"))
+		fmt.Fprint(w, londonForecast)
+	})
+	o := NewOpenMeteo(apiURL, geocodingURL, http.DefaultClient)
+
+	for range 2 {
+		weatherData, err := o.FetchWeather(context.Background(), models.Location{Name: "London, GB"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "London, GB", weatherData.Location)
+		assert.Equal(t, 15.5, weatherData.Temperature)
+		assert.Equal(t, 14.2, weatherData.FeelsLike)
+		assert.Equal(t, "Clear", weatherData.Condition)
+		assert.Equal(t, models.WeatherClearDay, weatherData.Code)
+		assert.Equal(t, 17.0, weatherData.High)
+		assert.Equal(t, 9.5, weatherData.Low)
+		assert.Equal(t, 72, weatherData.Humidity)
+		assert.Equal(t, 4.1, weatherData.WindSpeed)
+		assert.Equal(t, 230, weatherData.WindDirection)
+		assert.Equal(t, "05:52", weatherData.Sunrise.Format("15:04"))
+		assert.Equal(t, "20:12", weatherData.Sunset.Format("15:04"))
+		assert.Equal(t, []models.DailyForecast{
+			{
+				Date:      time.Date(2024, 4, 26, 12, 0, 0, 0, time.FixedZone("", 3600)),
+				High:      14.6,
+				Low:       8.1,
+				Condition: "Rain",
+				Code:      models.WeatherRain,
+			},
+			{
+				Date:      time.Date(2024, 4, 27, 12, 0, 0, 0, time.FixedZone("", 3600)),
+				High:      12.3,
+				Low:       7.0,
+				Condition: "Clouds",
+				Code:      models.WeatherCloudy,
+			},
+		}, weatherData.Forecast)
+	}
+	assert.Equal(t, 1, searched, "places are only looked up once")
+}
+
+func TestOpenMeteoLocationNotFound(t *testing.T) {
+	geocodingURL := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, londonSearch)
+	})
+	o := NewOpenMeteo("http://127.0.0.1:1", geocodingURL, http.DefaultClient)
+
+	weatherData, err := o.FetchWeather(context.Background(), models.Location{Name: "London, FR"})
+
+	assert.ErrorIs(t, err, ErrLocationNotFound)
+	assert.Nil(t, weatherData)
+}
+
+func TestOpenMeteoMalformedResponses(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want error
+	}{
+		"not JSON":       {`<html>`, ErrInvalidResponse},
+		"no current":     {`{"daily": {}}`, ErrNoCurrent},
+		"no temperature": {`{"current": {"weather_code": 0}}`, ErrNoTemperature},
+		"no condition":   {`{"current": {"temperature_2m": 1}}`, ErrNoCondition},
+		"no daily":       {`{"current": {"temperature_2m": 1, "weather_code": 0}}`, ErrNoDaily},
+		"ragged daily":   {`{"current": {"temperature_2m": 1, "weather_code": 0}, "daily": {"time": [1], "weather_code": [0]}}`, ErrNoDaily},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			apiURL := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.body)
+			})
+			o := NewOpenMeteo(apiURL, "", http.DefaultClient)
+
+			location := models.Location{Coordinates: &models.Coordinates{Lat: 51.5, Lon: -0.1}}
+			weatherData, err := o.FetchWeather(context.Background(), location)
+
+			assert.ErrorIs(t, err, tc.want)
+			assert.ErrorIs(t, err, ErrInvalidResponse)
+			assert.Nil(t, weatherData)
+		})
+	}
+}
+
+func TestWMOCondition(t *testing.T) {
+	tests := []struct {
+		code int
+		day  bool
+		want condition
+	}{
+		{0, true, condition{"Clear", "01d"}},
+		{0, false, condition{"Clear", "01n"}},
+		{2, true, condition{"Clouds", "02d"}},
+		{3, false, condition{"Clouds", "04n"}},
+		{48, true, condition{"Fog", "50d"}},
+		{55, true, condition{"Drizzle", "09d"}},
+		{63, true, condition{"Rain", "10d"}},
+		{81, true, condition{"Rain", "09d"}},
+		{75, true, condition{"Snow", "13d"}},
+		{86, false, condition{"Snow", "13n"}},
+		{96, true, condition{"Thunderstorm", "11d"}},
+		{42, true, condition{"Unknown", "01d"}},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, wmoCondition(tc.code, tc.day), "code %d", tc.code)
+	}
+}
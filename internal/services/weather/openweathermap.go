@@ -0,0 +1,79 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+const DefaultOpenWeatherMapURL = "https://api.openweathermap.org"
+
+// OpenWeatherMap fetches the weather straight from OpenWeatherMap's One Call
+// API, which needs a key subscribed to One Call 3.0.
+type OpenWeatherMap struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	geocoder   geocoder
+}
+
+func NewOpenWeatherMap(apiURL, apiKey string, httpClient *http.Client) (*OpenWeatherMap, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("openweathermap: %w", ErrNoAPIKey)
+	}
+	// OpenWeatherMap only takes its key as the appid query parameter, so
+	// make sure it's scrubbed from any URL that ends up in a log.
+	logging.AddSecrets(apiKey)
+	o := &OpenWeatherMap{
+		apiKey:     apiKey,
+		baseURL:    baseURL(apiURL, DefaultOpenWeatherMapURL),
+		httpClient: httpClient,
+	}
+	o.geocoder.search = o.search
+	return o, nil
+}
+
+func (o *OpenWeatherMap) Name() string { return "OpenWeatherMap" }
+func (o *OpenWeatherMap) Link() string { return "https://openweathermap.org" }
+
+func (o *OpenWeatherMap) FetchWeather(ctx context.Context, location models.Location) (*models.WeatherData, error) {
+	c, err := o.geocoder.coordinates(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{
+		"lat":     {fmt.Sprint(c.Lat)},
+		"lon":     {fmt.Sprint(c.Lon)},
+		"units":   {"metric"},
+		"exclude": {"minutely,hourly,alerts"},
+		"appid":   {o.apiKey},
+	}
+	var result oneCall
+	if err := getJSON(ctx, o.httpClient, "OpenWeatherMap", o.baseURL+"/data/3.0/onecall?"+q.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return result.weatherData(location.String())
+}
+
+// search looks a place up with OpenWeatherMap's geocoding API, which
+// understands "London, GB" as it is.
+func (o *OpenWeatherMap) search(ctx context.Context, name string) (models.Coordinates, error) {
+	q := url.Values{"q": {name}, "limit": {"1"}, "appid": {o.apiKey}}
+
+	var places []struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	}
+	if err := getJSON(ctx, o.httpClient, "OpenWeatherMap", o.baseURL+"/geo/1.0/direct?"+q.Encode(), nil, &places); err != nil {
+		return models.Coordinates{}, err
+	}
+	if len(places) == 0 {
+		return models.Coordinates{}, ErrLocationNotFound
+	}
+	return models.Coordinates{Lat: places[0].Lat, Lon: places[0].Lon}, nil
+}
@@ -0,0 +1,109 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging/logtest"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+func TestOpenWeatherMapFetchWeather(t *testing.T) {
+	var geocoded, fetched int
+	url := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "owm_key", q.Get("appid"))
+
+		switch r.URL.Path {
+		case "/geo/1.0/direct":
+			geocoded++
+			assert.Equal(t, "London, GB", q.Get("q"))
+			fmt.Fprint(w, `[{"name": "London", "lat": 51.5073, "lon": -0.1276, "country": "GB"}]`)
+		case "/data/3.0/onecall":
+			fetched++
+			assert.Equal(t, "51.5073", q.Get("lat"))
+			assert.Equal(t, "-0.1276", q.Get("lon"))
+			assert.Equal(t, "metric", q.Get("units"))
+			fmt.Fprint(w, londonOneCall)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	o, err := NewOpenWeatherMap(url, "owm_key", http.DefaultClient)
+	require.NoError(t, err)
+
+	for range 2 {
+		weatherData, err := o.FetchWeather(context.Background(), models.Location{Name: "London, GB"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "London, GB", weatherData.Location)
+		assertLondon(t, weatherData)
+	}
+	assert.Equal(t, 1, geocoded, "places are only looked up once")
+	assert.Equal(t, 2, fetched)
+}
+
+func TestOpenWeatherMapFetchWeatherByCoordinates(t *testing.T) {
+	url := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/data/3.0/onecall", r.URL.Path, "coordinates aren't geocoded")
+		assert.Equal(t, "38.7", r.URL.Query().Get("lat"))
+		fmt.Fprint(w, londonOneCall)
+	})
+	o, err := NewOpenWeatherMap(url, "owm_key", http.DefaultClient)
+	require.NoError(t, err)
+
+	location := models.Location{Coordinates: &models.Coordinates{Lat: 38.7, Lon: -9.1}}
+	weatherData, err := o.FetchWeather(context.Background(), location)
+
+	require.NoError(t, err)
+	assert.Equal(t, "38.7°N, 9.1°W", weatherData.Location)
+}
+
+func TestOpenWeatherMapLocationNotFound(t *testing.T) {
+	url := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	o, err := NewOpenWeatherMap(url, "owm_key", http.DefaultClient)
+	require.NoError(t, err)
+
+	weatherData, err := o.FetchWeather(context.Background(), models.Location{Name: "Atlantis"})
+
+	assert.ErrorIs(t, err, ErrLocationNotFound)
+	assert.ErrorContains(t, err, "finding Atlantis")
+	assert.Nil(t, weatherData)
+}
+
+func TestOpenWeatherMapAPIError(t *testing.T) {
+	url := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"cod": 401, "message": "Invalid API key."}`)
+	})
+	o, err := NewOpenWeatherMap(url, "owm_key", http.DefaultClient)
+	require.NoError(t, err)
+
+	location := models.Location{Coordinates: &models.Coordinates{Lat: 51.5, Lon: -0.1}}
+	weatherData, err := o.FetchWeather(context.Background(), location)
+
+	assert.ErrorContains(t, err, "OpenWeatherMap API returned status: 401 Unauthorized")
+	assert.Nil(t, weatherData)
+}
+
+func TestOpenWeatherMapKeyStaysOutOfErrors(t *testing.T) {
+	const key = "owm_secret_key"
+	logs := logtest.Capture(t)
+	o, err := NewOpenWeatherMap(closedServerURL(), key, http.DefaultClient)
+	require.NoError(t, err)
+
+	location := models.Location{Coordinates: &models.Coordinates{Lat: 51.5, Lon: -0.1}}
+	_, err = o.FetchWeather(context.Background(), location)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "appid=REDACTED", "the key can only go in the URL, so it's redacted there")
+	assert.NotContains(t, err.Error(), key)
+	assert.NotContains(t, logs.String(), key)
+}
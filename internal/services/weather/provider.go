@@ -0,0 +1,88 @@
+// Package weather fetches the current weather and forecast for the widget
+// from one of several providers.
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// Provider names, as set in weather.provider.
+const (
+	ProviderBreeze         = "breeze"
+	ProviderOpenWeatherMap = "openweathermap"
+	ProviderOpenMeteo      = "open-meteo"
+)
+
+// Providers lists every provider New knows.
+var Providers = []string{ProviderBreeze, ProviderOpenWeatherMap, ProviderOpenMeteo}
+
+// ErrNoAPIKey is returned when creating a provider that needs an API key
+// without one.
+var ErrNoAPIKey = errors.New("no API key")
+
+// Provider fetches the weather for a location. Name and Link credit it in
+// the widget.
+type Provider interface {
+	Name() string
+	Link() string
+	FetchWeather(ctx context.Context, location models.Location) (*models.WeatherData, error)
+}
+
+// Config selects and configures a Provider. Empty URLs use the provider's
+// public API.
+type Config struct {
+	Provider string
+	APIKey   string
+	URL      string
+	// GeocodingURL is only used by Open-Meteo, whose place search lives on a
+	// separate host.
+	GeocodingURL string
+	// BreezeLink is where breeze's credit links to.
+	BreezeLink string
+	HTTPClient *http.Client
+}
+
+// New creates the provider named by cfg.Provider.
+func New(cfg Config) (Provider, error) {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	switch cfg.Provider {
+	case ProviderBreeze:
+		b, err := NewBreeze(cfg.URL, cfg.APIKey, cfg.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.BreezeLink != "" {
+			b.link = cfg.BreezeLink
+		}
+		return b, nil
+	case ProviderOpenWeatherMap:
+		o, err := NewOpenWeatherMap(cfg.URL, cfg.APIKey, cfg.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		return o, nil
+	case ProviderOpenMeteo:
+		return NewOpenMeteo(cfg.URL, cfg.GeocodingURL, cfg.HTTPClient), nil
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q, want one of %s",
+			cfg.Provider, strings.Join(Providers, ", "))
+	}
+}
+
+// baseURL returns url without a trailing slash, or def if it's empty.
+func baseURL(url, def string) string {
+	if url == "" {
+		return def
+	}
+	return strings.TrimSuffix(url, "/")
+}
@@ -0,0 +1,167 @@
+package weather
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// londonOneCall is OpenWeatherMap's One Call response for London on a
+// spring day, at UTC+1.
+const londonOneCall = `{
+	"timezone_offset": 3600,
+	"current": {
+		"sunrise": 1714020720,
+		"sunset": 1714072320,
+		"temp": 15.5,
+		"feels_like": 14.2,
+		"humidity": 72,
+		"wind_speed": 4.1,
+		"wind_deg": 230,
+		"weather": [{"id": 800, "main": "Clear", "description": "clear sky", "icon": "01d"}]
+	},
+	"daily": [
+		{"dt": 1714046400, "temp": {"min": 9.5, "max": 17.0}, "weather": [{"main": "Clear", "icon": "01d"}]},
+		{"dt": 1714132800, "temp": {"min": 8.1, "max": 14.6}, "weather": [{"main": "Rain", "icon": "10d"}]},
+		{"dt": 1714219200, "temp": {"min": 7.0, "max": 12.3}, "weather": [{"main": "Clouds", "icon": "04d"}]}
+	]
+}`
+
+// newTestServer serves handler locally and returns its URL.
+func newTestServer(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// closedServerURL returns the URL of a server that's no longer listening.
+func closedServerURL() string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+// assertLondon checks w is the weather in londonOneCall.
+func assertLondon(t *testing.T, w *models.WeatherData) {
+	t.Helper()
+	require.NotNil(t, w)
+	assert.Equal(t, 15.5, w.Temperature)
+	assert.Equal(t, "Clear", w.Condition)
+	assert.Equal(t, models.WeatherClearDay, w.Code)
+	assert.Equal(t, 14.2, w.FeelsLike)
+	assert.Equal(t, 17.0, w.High)
+	assert.Equal(t, 9.5, w.Low)
+	assert.Equal(t, 72, w.Humidity)
+	assert.Equal(t, 4.1, w.WindSpeed)
+	assert.Equal(t, 230, w.WindDirection)
+	assert.Equal(t, "05:52", w.Sunrise.Format("15:04"), "in the location's time zone")
+	assert.Equal(t, "20:12", w.Sunset.Format("15:04"))
+	assert.Equal(t, []models.DailyForecast{
+		{
+			Date:      time.Date(2024, 4, 26, 13, 0, 0, 0, time.FixedZone("", 3600)),
+			High:      14.6,
+			Low:       8.1,
+			Condition: "Rain",
+			Code:      models.WeatherRain,
+		},
+		{
+			Date:      time.Date(2024, 4, 27, 13, 0, 0, 0, time.FixedZone("", 3600)),
+			High:      12.3,
+			Low:       7.0,
+			Condition: "Clouds",
+			Code:      models.WeatherCloudy,
+		},
+	}, w.Forecast, "today is left out")
+	assert.NotZero(t, w.LastUpdated)
+}
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		cfg     Config
+		want    string
+		wantErr error
+	}{
+		"breeze":                  {Config{Provider: ProviderBreeze, APIKey: "key"}, "Breeze API", nil},
+		"breeze without key":      {Config{Provider: ProviderBreeze}, "", ErrNoAPIKey},
+		"openweathermap":          {Config{Provider: ProviderOpenWeatherMap, APIKey: "key"}, "OpenWeatherMap", nil},
+		"openweathermap no key":   {Config{Provider: ProviderOpenWeatherMap}, "", ErrNoAPIKey},
+		"open-meteo needs no key": {Config{Provider: ProviderOpenMeteo}, "Open-Meteo", nil},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := New(tc.cfg)
+
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, p)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, p.Name())
+		})
+	}
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	_, err := New(Config{Provider: "metoffice"})
+
+	assert.ErrorContains(t, err, `unknown weather provider "metoffice"`)
+}
+
+func TestNewDefaults(t *testing.T) {
+	p, err := New(Config{Provider: ProviderBreeze, APIKey: "key"})
+	require.NoError(t, err)
+
+	b := p.(*Breeze)
+	assert.Equal(t, DefaultBreezeURL, b.baseURL)
+	assert.Equal(t, DefaultBreezeLink, b.Link())
+	assert.Equal(t, 10*time.Second, b.httpClient.Timeout)
+
+	p, err = New(Config{Provider: ProviderBreeze, APIKey: "key", URL: "http://breeze:8080/", BreezeLink: "https://example.com/breeze"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://breeze:8080", p.(*Breeze).baseURL)
+	assert.Equal(t, "https://example.com/breeze", p.Link())
+
+	p, err = New(Config{Provider: ProviderOpenMeteo})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultOpenMeteoURL, p.(*OpenMeteo).baseURL)
+	assert.Equal(t, DefaultOpenMeteoGeocodingURL, p.(*OpenMeteo).geocodingURL)
+}
+
+func TestConditionCode(t *testing.T) {
+	for icon, want := range map[string]string{
+		"01d": models.WeatherClearDay,
+		"01n": models.WeatherClearNight,
+		"02d": models.WeatherPartlyCloudyDay,
+		"02n": models.WeatherPartlyCloudyNight,
+		"03d": models.WeatherCloudy,
+		"04n": models.WeatherCloudy,
+		"09d": models.WeatherShowers,
+		"10n": models.WeatherRain,
+		"11d": models.WeatherThunderstorm,
+		"13d": models.WeatherSnow,
+		"50n": models.WeatherFog,
+		"99x": models.WeatherCloudy,
+	} {
+		assert.Equal(t, want, condition{Icon: icon}.code(), icon)
+	}
+}
+
+func TestWeatherCodesHaveSymbols(t *testing.T) {
+	sprite, err := os.ReadFile(filepath.Join("..", "..", "..", "static", "icons", "icons.svg"))
+	require.NoError(t, err)
+
+	for _, code := range models.WeatherCodes {
+		assert.Contains(t, string(sprite), `<symbol id="weather-`+code+`"`)
+	}
+}
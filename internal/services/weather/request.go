@@ -0,0 +1,97 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// ErrLocationNotFound is returned when a provider can't find a place by
+// name.
+var ErrLocationNotFound = errors.New("location not found")
+
+// maxErrorBody caps how much of an error response is logged.
+const maxErrorBody = 512
+
+// getJSON fetches url from the provider named name, with header, and decodes
+// the JSON response into v. Errors are redacted, since some providers only
+// take their key in the URL.
+func getJSON(ctx context.Context, hc *http.Client, name, url string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return logging.RedactError(err)
+	}
+	for k, vals := range header {
+		req.Header[k] = vals
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		err = logging.RedactError(err)
+		logging.Error("HTTP request failed", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		logging.Warn("%s API returned status: %s, body: %s", name, resp.Status, body)
+		return fmt.Errorf("%s API returned status: %s", name, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	return nil
+}
+
+// geocoder remembers where named places are, since the weather is fetched
+// every few minutes but cities rarely move.
+type geocoder struct {
+	mu     sync.Mutex
+	places map[string]models.Coordinates
+	search func(ctx context.Context, name string) (models.Coordinates, error)
+}
+
+// coordinates returns location's coordinates, looking its name up if it
+// has none.
+func (g *geocoder) coordinates(ctx context.Context, location models.Location) (models.Coordinates, error) {
+	if location.Coordinates != nil {
+		return *location.Coordinates, nil
+	}
+
+	g.mu.Lock()
+	c, ok := g.places[location.Name]
+	g.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+
+	c, err := g.search(ctx, location.Name)
+	if err != nil {
+		return c, fmt.Errorf("finding %s: %w", location.Name, err)
+	}
+
+	g.mu.Lock()
+	if g.places == nil {
+		g.places = make(map[string]models.Coordinates)
+	}
+	g.places[location.Name] = c
+	g.mu.Unlock()
+	return c, nil
+}
+
+// splitPlace splits a name such as "London, GB" into the place and an
+// upper-cased country code, which is empty if there isn't one.
+func splitPlace(name string) (place, country string) {
+	place, country, _ = strings.Cut(name, ",")
+	return strings.TrimSpace(place), strings.ToUpper(strings.TrimSpace(country))
+}
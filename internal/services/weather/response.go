@@ -0,0 +1,161 @@
+package weather
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// ErrInvalidResponse is wrapped by every error for a provider response we
+// can't use, whether it isn't valid JSON or is missing something we need,
+// so callers can check for any of them with errors.Is.
+var ErrInvalidResponse = errors.New("invalid weather response")
+
+var (
+	// ErrNoWeather is returned when breeze's response has no weather in it.
+	ErrNoWeather     = fmt.Errorf("%w: no weather", ErrInvalidResponse)
+	ErrNoCurrent     = fmt.Errorf("%w: no current conditions", ErrInvalidResponse)
+	ErrNoTemperature = fmt.Errorf("%w: no temperature", ErrInvalidResponse)
+	ErrNoCondition   = fmt.Errorf("%w: no weather condition", ErrInvalidResponse)
+	ErrNoDaily       = fmt.Errorf("%w: no daily forecast", ErrInvalidResponse)
+)
+
+// oneCall is an OpenWeatherMap One Call response, which breeze wraps. See
+// https://openweathermap.org/api/one-call-3.
+type oneCall struct {
+	// TimezoneOffset is the location's offset from UTC, in seconds.
+	TimezoneOffset int             `json:"timezone_offset"`
+	Current        *currentWeather `json:"current"`
+	Daily          []dailyWeather  `json:"daily"`
+}
+
+type currentWeather struct {
+	Sunrise   int64       `json:"sunrise"`
+	Sunset    int64       `json:"sunset"`
+	Temp      *float64    `json:"temp"`
+	FeelsLike float64     `json:"feels_like"`
+	Humidity  int         `json:"humidity"`
+	WindSpeed float64     `json:"wind_speed"`
+	WindDeg   int         `json:"wind_deg"`
+	Weather   []condition `json:"weather"`
+}
+
+type dailyWeather struct {
+	Dt   int64 `json:"dt"`
+	Temp struct {
+		Min float64 `json:"min"`
+		Max float64 `json:"max"`
+	} `json:"temp"`
+	Weather []condition `json:"weather"`
+}
+
+type condition struct {
+	Main string `json:"main"`
+	Icon string `json:"icon"`
+}
+
+// weatherData checks the response has everything the widget shows and
+// converts it.
+func (r *oneCall) weatherData(location string) (*models.WeatherData, error) {
+	switch {
+	case r.Current == nil:
+		return nil, ErrNoCurrent
+	case r.Current.Temp == nil:
+		return nil, ErrNoTemperature
+	case len(r.Current.Weather) == 0:
+		return nil, ErrNoCondition
+	case len(r.Daily) == 0:
+		return nil, ErrNoDaily
+	}
+
+	current := r.Current
+	today := r.Daily[0]
+	zone := time.FixedZone("", r.TimezoneOffset)
+
+	cond := current.Weather[0].withDefaults()
+
+	forecast := make([]models.DailyForecast, 0, len(r.Daily)-1)
+	for _, day := range r.Daily[1:] {
+		var c condition
+		if len(day.Weather) > 0 {
+			c = day.Weather[0]
+		}
+		c = c.withDefaults()
+		forecast = append(forecast, models.DailyForecast{
+			Date:      unixTime(day.Dt, zone),
+			High:      day.Temp.Max,
+			Low:       day.Temp.Min,
+			Condition: c.Main,
+			Code:      c.code(),
+		})
+	}
+
+	return &models.WeatherData{
+		Location:      location,
+		Temperature:   *current.Temp,
+		FeelsLike:     current.FeelsLike,
+		High:          today.Temp.Max,
+		Low:           today.Temp.Min,
+		Humidity:      current.Humidity,
+		WindSpeed:     current.WindSpeed,
+		WindDirection: current.WindDeg,
+		Sunrise:       unixTime(current.Sunrise, zone),
+		Sunset:        unixTime(current.Sunset, zone),
+		Condition:     cond.Main,
+		Code:          cond.code(),
+		Forecast:      forecast,
+		LastUpdated:   time.Now(),
+	}, nil
+}
+
+func (c condition) withDefaults() condition {
+	if c.Main == "" {
+		c.Main = "Unknown"
+	}
+	if c.Icon == "" {
+		c.Icon = "01d"
+	}
+	return c
+}
+
+// code maps an OpenWeatherMap icon, such as "10n", onto a condition code.
+// See https://openweathermap.org/weather-conditions.
+func (c condition) code() string {
+	night := strings.HasSuffix(c.Icon, "n")
+	switch strings.TrimRight(c.Icon, "dn") {
+	case "01":
+		if night {
+			return models.WeatherClearNight
+		}
+		return models.WeatherClearDay
+	case "02":
+		if night {
+			return models.WeatherPartlyCloudyNight
+		}
+		return models.WeatherPartlyCloudyDay
+	case "09":
+		return models.WeatherShowers
+	case "10":
+		return models.WeatherRain
+	case "11":
+		return models.WeatherThunderstorm
+	case "13":
+		return models.WeatherSnow
+	case "50":
+		return models.WeatherFog
+	default:
+		return models.WeatherCloudy
+	}
+}
+
+// unixTime converts seconds since the epoch to a time in zone, leaving zero
+// as the zero time since polar days and nights have no sunrise or sunset.
+func unixTime(sec int64, zone *time.Location) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).In(zone)
+}
@@ -0,0 +1,39 @@
+package weather
+
+import (
+	"context"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// Source exposes a Provider's weather for the current location as a
+// DataUpdater source.
+type Source struct {
+	provider  Provider
+	locations *Locations
+	interval  time.Duration
+}
+
+func NewSource(provider Provider, locations *Locations, interval time.Duration) *Source {
+	return &Source{provider: provider, locations: locations, interval: interval}
+}
+
+func (s *Source) Name() string            { return "weather" }
+func (s *Source) Key() string             { return models.KeyWeather }
+func (s *Source) Interval() time.Duration { return s.interval }
+
+func (s *Source) Fetch(ctx context.Context) (any, error) {
+	location := s.locations.Current()
+	if location.IsZero() {
+		return nil, nil
+	}
+
+	w, err := s.provider.FetchWeather(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	w.Provider = s.provider.Name()
+	w.ProviderURL = s.provider.Link()
+	return w, nil
+}
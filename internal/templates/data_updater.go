@@ -1,159 +1,441 @@
 package templates
 
 import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/josephburgess/joeburgess.dev/internal/logging"
 	"github.com/josephburgess/joeburgess.dev/internal/models"
-	"github.com/josephburgess/joeburgess.dev/internal/services/github"
-	"github.com/josephburgess/joeburgess.dev/internal/services/weather"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+// Failing sources are retried after minBackoff, doubling on each consecutive
+// failure up to maxBackoff.
+const (
+	minBackoff = 30 * time.Second
+	maxBackoff = time.Hour
 )
 
 type DataUpdater struct {
-	mu              sync.RWMutex
-	data            *PageData
-	githubService   *github.Client
-	weatherService  *weather.Client
-	weatherLocation string
-	lastUpdated     time.Time
-	maxAge          time.Duration
-	updating        sync.Mutex
-}
-
-func NewDataUpdater(
-	githubService *github.Client,
-	weatherService *weather.Client,
-	weatherLocation string,
-	profileImage string,
-	githubURL string,
-	linkedInURL string,
-	breezeURL string,
-	email string,
-) *DataUpdater {
+	mu          sync.RWMutex
+	data        *PageData
+	sources     []Source
+	results     map[string]any
+	state       map[string]*sourceState
+	lastUpdated time.Time
+	now         func() time.Time
+
+	// repoSelection orders repositories merged from several forges.
+	repoSelection forge.RepoSelection
+
+	// updating is held for the duration of every refresh so only one runs at
+	// a time. It is only ever acquired through startUpdate.
+	updating sync.Mutex
+	current  *updateRecord
+	history  []*updateRecord
+
+	snapshotPath string
+	saveMu       sync.Mutex
+
+	// ctx is cancelled on Shutdown so background refreshes abandon any
+	// outstanding fetches.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	bgMu     sync.Mutex
+	bg       sync.WaitGroup
+	shutdown bool
+}
+
+type sourceState struct {
+	lastSuccess time.Time
+	lastError   error
+	lastErrorAt time.Time
+	failures    int
+	nextAttempt time.Time
+	// invalidatedAt is set by Refresh to make the source due immediately,
+	// until a fetch that started after it completes.
+	invalidatedAt time.Time
+}
+
+func NewDataUpdater(profileImage string, links []models.Link) *DataUpdater {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &DataUpdater{
 		data: &PageData{
 			ProfileImage: profileImage,
-			GithubURL:    githubURL,
-			LinkedInURL:  linkedInURL,
-			BreezeURL:    breezeURL,
-			Email:        email,
+			Links:        links,
 		},
-		githubService:   githubService,
-		weatherService:  weatherService,
-		weatherLocation: weatherLocation,
-		maxAge:          1 * time.Hour,
+		results:       make(map[string]any),
+		state:         make(map[string]*sourceState),
+		now:           time.Now,
+		repoSelection: forge.DefaultRepoSelection,
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// SetRepoSelection sets the order repositories from several forges are
+// merged in. It should match the selection each forge was given, and be
+// set before the updater is first used.
+func (du *DataUpdater) SetRepoSelection(sel forge.RepoSelection) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+	du.repoSelection = sel
+}
+
+// Register adds sources to be fetched on the next update. Sources should be
+// registered before the updater is first used.
+func (du *DataUpdater) Register(sources ...Source) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+	for _, src := range sources {
+		du.sources = append(du.sources, src)
+		du.state[src.Name()] = &sourceState{}
 	}
 }
 
 func (du *DataUpdater) GetData() PageData {
 	du.mu.RLock()
-	stale := time.Since(du.lastUpdated) > du.maxAge
+	due := len(du.dueSources(du.now())) > 0
 	data := du.copyData()
 	du.mu.RUnlock()
 
-	if stale {
-		go du.UpdateIfStale()
+	if due {
+		du.goBackground(du.UpdateIfStale)
 	}
 
 	return data
 }
 
-// UpdateIfStale triggers an update only if one isn't already running.
-func (du *DataUpdater) UpdateIfStale() {
-	if !du.updating.TryLock() {
+// Start refreshes each source in the background as it falls due, until
+// Shutdown is called.
+func (du *DataUpdater) Start() {
+	du.goBackground(du.run)
+}
+
+func (du *DataUpdater) run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		du.UpdateIfStale(ctx)
+
+		du.mu.RLock()
+		wait := du.nextAttempt().Sub(du.now())
+		du.mu.RUnlock()
+
+		// Page views and manual updates can also refresh sources, so never
+		// sleep for too long on a stale plan.
+		timer.Reset(max(min(wait, time.Minute), time.Second))
+	}
+}
+
+// UpdateIfStale refreshes any sources that are due, unless an update is
+// already running. A source is due once its interval has elapsed since it
+// last succeeded, or its backoff has elapsed since it last failed.
+func (du *DataUpdater) UpdateIfStale(ctx context.Context) {
+	rec, started := du.startUpdate()
+	if !started {
 		return
 	}
-	defer du.updating.Unlock()
 
 	du.mu.RLock()
-	fresh := time.Since(du.lastUpdated) <= du.maxAge
+	due := du.dueSources(du.now())
 	du.mu.RUnlock()
 
-	if fresh {
+	if len(due) == 0 {
+		du.finishUpdate(rec, nil, false)
 		return
 	}
 
-	du.Update()
+	du.finishUpdate(rec, du.refresh(ctx, due), true)
 }
 
-func (du *DataUpdater) Update() {
-	var (
-		wg           sync.WaitGroup
-		repos        []models.Repository
-		activities   []models.Activity
-		weatherData  *models.WeatherData
-	)
+// TriggerUpdate starts a full update in the background and returns its
+// status. If an update is already running, no new one is started and the
+// running update's status is returned instead, with coalesced set. The update
+// is tied to the updater's lifetime rather than the caller's.
+func (du *DataUpdater) TriggerUpdate() (status UpdateStatus, coalesced bool) {
+	rec, started := du.startUpdate()
+	if !started {
+		return rec.status(), true
+	}
 
-	wg.Add(3)
+	du.mu.RLock()
+	sources := du.sources
+	du.mu.RUnlock()
 
-	go func() {
-		defer wg.Done()
-		r, err := du.githubService.FetchRepositories()
-		if err != nil {
-			logging.Error("Failed to fetch repositories", err)
-			return
+	ok := du.goBackground(func(ctx context.Context) {
+		du.finishUpdate(rec, du.refresh(ctx, sources), true)
+	})
+	if !ok {
+		du.finishUpdate(rec, map[string]error{"": context.Canceled}, true)
+	}
+
+	return rec.status(), false
+}
+
+// Refresh refreshes the sources storing their results under any of keys in
+// the background, regardless of their schedules, for when we know their data
+// has changed. If an update is already running they're refreshed as soon as
+// it finishes.
+func (du *DataUpdater) Refresh(keys ...string) {
+	du.mu.Lock()
+	now := du.now()
+	for _, src := range du.sources {
+		if slices.Contains(keys, src.Key()) {
+			du.state[src.Name()].invalidatedAt = now
 		}
-		repos = r
-	}()
+	}
+	du.mu.Unlock()
+
+	du.goBackground(du.UpdateIfStale)
+}
 
+// Shutdown cancels any in-flight background updates and waits for them to
+// return, or for ctx to expire. No new background updates are started once
+// Shutdown has been called.
+func (du *DataUpdater) Shutdown(ctx context.Context) error {
+	du.bgMu.Lock()
+	du.shutdown = true
+	du.bgMu.Unlock()
+
+	du.cancel()
+
+	done := make(chan struct{})
 	go func() {
-		defer wg.Done()
-		a, err := du.githubService.FetchActivity()
-		if err != nil {
-			logging.Error("Failed to fetch GitHub activity", err)
-			return
-		}
-		activities = a
+		du.bg.Wait()
+		close(done)
 	}()
 
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// goBackground runs fn in a goroutine tracked by Shutdown, and reports
+// whether it was started.
+func (du *DataUpdater) goBackground(fn func(context.Context)) bool {
+	du.bgMu.Lock()
+	defer du.bgMu.Unlock()
+
+	if du.shutdown {
+		return false
+	}
+
+	du.bg.Add(1)
 	go func() {
-		defer wg.Done()
-		if du.weatherLocation == "" {
+		defer du.bg.Done()
+		fn(du.ctx)
+	}()
+	return true
+}
+
+// Update fetches every registered source, ignoring schedules and backoff. If
+// another update is running it waits for that to finish first.
+func (du *DataUpdater) Update(ctx context.Context) {
+	for {
+		rec, started := du.startUpdate()
+		if started {
+			du.mu.RLock()
+			sources := du.sources
+			du.mu.RUnlock()
+
+			du.finishUpdate(rec, du.refresh(ctx, sources), true)
 			return
 		}
-		w, err := du.weatherService.FetchWeather(du.weatherLocation)
-		if err != nil {
-			logging.Error("Failed to fetch weather", err)
+
+		select {
+		case <-rec.done:
+		case <-ctx.Done():
 			return
 		}
-		weatherData = w
-	}()
+	}
+}
+
+// refresh fetches sources concurrently and stores the results. It returns
+// the errors from any sources that failed, keyed by source name, or the
+// context's error under "" if the refresh was cancelled.
+func (du *DataUpdater) refresh(ctx context.Context, sources []Source) map[string]error {
+	du.mu.RLock()
+	started := du.now()
+	du.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	results := make([]any, len(sources))
+	errs := make([]error, len(sources))
+
+	for i, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = src.Fetch(ctx)
+		}()
+	}
 
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		logging.Warn("Data update cancelled: %v", err)
+		return map[string]error{"": err}
+	}
+
 	du.mu.Lock()
-	defer du.mu.Unlock()
+	succeeded := du.applyResults(sources, started, results, errs)
+	du.mu.Unlock()
 
-	if repos != nil {
-		du.data.GithubRepos = repos
+	if succeeded {
+		if err := du.saveSnapshot(); err != nil {
+			logging.Error("Failed to save data snapshot", err)
+		}
 	}
-	if activities != nil {
-		du.data.GitHubActivities = activities
+
+	failed := make(map[string]error)
+	for i, src := range sources {
+		if errs[i] != nil {
+			failed[src.Name()] = errs[i]
+		}
 	}
-	if weatherData != nil {
-		du.data.Weather = weatherData
+	return failed
+}
+
+// applyResults records the outcome of fetching sources and reports whether
+// any of them succeeded. It must be called with du.mu held.
+func (du *DataUpdater) applyResults(sources []Source, started time.Time, results []any, errs []error) bool {
+	succeeded := false
+	now := du.now()
+	for i, src := range sources {
+		st := du.state[src.Name()]
+		if !st.invalidatedAt.After(started) {
+			st.invalidatedAt = time.Time{}
+		}
+
+		if err := errs[i]; err != nil {
+			st.failures++
+			st.lastError = err
+			st.lastErrorAt = now
+			st.nextAttempt = now.Add(backoff(st.failures))
+			var retry interface{ RetryAt() time.Time }
+			if errors.As(err, &retry) && retry.RetryAt().After(st.nextAttempt) {
+				st.nextAttempt = retry.RetryAt()
+			}
+			logging.Error("Failed to fetch "+src.Name(), err)
+			logging.Warn("Retrying %s in %s (attempt %d)", src.Name(), st.nextAttempt.Sub(now).Round(time.Second), st.failures+1)
+			continue
+		}
+
+		if results[i] != nil {
+			du.results[src.Key()] = results[i]
+		}
+		st.failures = 0
+		st.lastError = nil
+		st.lastSuccess = now
+		st.nextAttempt = now.Add(src.Interval())
+		succeeded = true
 	}
 
-	now := time.Now()
 	du.data.LastUpdated = now.Format("Jan 02 2006 15:04:05")
 	du.lastUpdated = now
+	return succeeded
+}
+
+// backoff returns the delay before retrying a source that has failed
+// failures times in a row, with ±20% jitter so sources sharing an upstream
+// don't retry in lockstep.
+func backoff(failures int) time.Duration {
+	d := maxBackoff
+	if failures < 32 {
+		d = min(minBackoff<<(failures-1), maxBackoff)
+	}
+	jitter := d / 5
+	return d - jitter + rand.N(2*jitter+1)
+}
+
+// dueSources must be called with du.mu held.
+func (du *DataUpdater) dueSources(now time.Time) []Source {
+	var due []Source
+	for _, src := range du.sources {
+		st := du.state[src.Name()]
+		if !st.invalidatedAt.IsZero() || !now.Before(st.nextAttempt) {
+			due = append(due, src)
+		}
+	}
+	return due
+}
+
+// hasInvalidated must be called with du.mu held.
+func (du *DataUpdater) hasInvalidated() bool {
+	for _, st := range du.state {
+		if !st.invalidatedAt.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// nextAttempt must be called with du.mu held.
+func (du *DataUpdater) nextAttempt() time.Time {
+	var next time.Time
+	for _, src := range du.sources {
+		at := du.state[src.Name()].nextAttempt
+		if next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+	return next
 }
 
 func (du *DataUpdater) copyData() PageData {
 	d := PageData{
-		ProfileImage:     du.data.ProfileImage,
-		GithubURL:        du.data.GithubURL,
-		LinkedInURL:      du.data.LinkedInURL,
-		BreezeURL:        du.data.BreezeURL,
-		Email:            du.data.Email,
-		IsDarkMode:       du.data.IsDarkMode,
-		LastUpdated:      du.data.LastUpdated,
-		GithubRepos:      du.data.GithubRepos,
-		GitHubActivities: du.data.GitHubActivities,
-	}
-	if du.data.Weather != nil {
-		weatherCopy := *du.data.Weather
+		ProfileImage: du.data.ProfileImage,
+		Links:        du.data.Links,
+		IsDarkMode:   du.data.IsDarkMode,
+		LastUpdated:  du.data.LastUpdated,
+		Results:      make(map[string]any, len(du.results)),
+		Sources:      make(map[string]SourceStatus, len(du.sources)),
+	}
+	for key, v := range du.results {
+		d.Results[key] = v
+	}
+
+	now := du.now()
+	for _, src := range du.sources {
+		st := du.state[src.Name()]
+		status := SourceStatus{
+			Name:        src.Name(),
+			LastSuccess: st.lastSuccess,
+			LastErrorAt: st.lastErrorAt,
+			NextAttempt: st.nextAttempt,
+			Stale:       st.lastSuccess.IsZero() || now.Sub(st.lastSuccess) > src.Interval(),
+		}
+		if st.lastError != nil {
+			status.LastError = st.lastError.Error()
+		}
+		d.Sources[src.Key()] = status
+	}
+
+	d.Repos = mergeRepositories(forgeResults[models.Repository](du, models.KeyRepositories), du.repoSelection)
+	d.Activities = mergeActivities(forgeResults[models.Activity](du, models.KeyActivity))
+	if stats, ok := du.results[models.KeyStats].(*models.GitHubStats); ok && stats != nil {
+		d.Stats = stats
+	}
+	if cal, ok := du.results[models.KeyContributions].(*models.ContributionCalendar); ok && cal != nil {
+		d.Contributions = cal
+	}
+	if weather, ok := du.results[models.KeyWeather].(*models.WeatherData); ok && weather != nil {
+		weatherCopy := *weather
 		d.Weather = &weatherCopy
 	}
 	return d
@@ -0,0 +1,255 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	name     string
+	key      string
+	interval time.Duration
+	fetch    func(ctx context.Context) (any, error)
+	calls    atomic.Int32
+}
+
+func (s *fakeSource) Name() string            { return s.name }
+func (s *fakeSource) Key() string             { return s.key }
+func (s *fakeSource) Interval() time.Duration { return s.interval }
+
+func (s *fakeSource) Fetch(ctx context.Context) (any, error) {
+	s.calls.Add(1)
+	return s.fetch(ctx)
+}
+
+func returning(v any, err error) func(context.Context) (any, error) {
+	return func(context.Context) (any, error) { return v, err }
+}
+
+func TestUpdateStoresResultsByKey(t *testing.T) {
+	repos := []models.Repository{{Name: "repo1"}}
+	activities := []models.Activity{{RepoName: "user/repo1"}}
+	weather := &models.WeatherData{Location: "London", Temperature: 12}
+
+	du := NewDataUpdater("", nil)
+	du.Register(
+		&fakeSource{name: "repos", key: models.KeyRepositories, interval: time.Hour, fetch: returning(repos, nil)},
+		&fakeSource{name: "activity", key: models.KeyActivity, interval: time.Hour, fetch: returning(activities, nil)},
+		&fakeSource{name: "weather", key: models.KeyWeather, interval: time.Hour, fetch: returning(weather, nil)},
+		&fakeSource{name: "custom", key: "quote", interval: time.Hour, fetch: returning("hello", nil)},
+	)
+
+	du.Update(context.Background())
+	data := du.GetData()
+
+	assert.Equal(t, repos, data.Repos)
+	assert.Equal(t, activities, data.Activities)
+	assert.Equal(t, weather, data.Weather)
+	assert.NotSame(t, weather, data.Weather)
+	assert.Equal(t, "hello", data.Results["quote"])
+	assert.NotEmpty(t, data.LastUpdated)
+}
+
+func TestUpdateKeepsPreviousValueOnError(t *testing.T) {
+	var fail atomic.Bool
+	src := &fakeSource{name: "repos", key: models.KeyRepositories, interval: time.Hour}
+	src.fetch = func(context.Context) (any, error) {
+		if fail.Load() {
+			return nil, errors.New("boom")
+		}
+		return []models.Repository{{Name: "repo1"}}, nil
+	}
+	empty := &fakeSource{name: "empty", key: models.KeyWeather, interval: time.Hour, fetch: returning(nil, nil)}
+
+	du := NewDataUpdater("", nil)
+	du.Register(src, empty)
+
+	du.Update(context.Background())
+	fail.Store(true)
+	du.Update(context.Background())
+
+	data := du.GetData()
+	assert.Equal(t, []models.Repository{{Name: "repo1"}}, data.Repos)
+	assert.Nil(t, data.Weather)
+}
+
+func TestGetDataRefreshesOnlyStaleSources(t *testing.T) {
+	fresh := &fakeSource{name: "fresh", key: "fresh", interval: time.Hour, fetch: returning(1, nil)}
+	stale := &fakeSource{name: "stale", key: "stale", interval: time.Nanosecond, fetch: returning(2, nil)}
+
+	du := NewDataUpdater("", nil)
+	du.Register(fresh, stale)
+	du.Update(context.Background())
+
+	time.Sleep(time.Millisecond)
+	du.GetData()
+	assert.NoError(t, du.Shutdown(context.Background()))
+
+	assert.EqualValues(t, 1, fresh.calls.Load())
+	assert.EqualValues(t, 2, stale.calls.Load())
+}
+
+func TestShutdownCancelsInFlightUpdate(t *testing.T) {
+	var inFlight sync.WaitGroup
+	inFlight.Add(2)
+	blockUntilCancelled := func(ctx context.Context) (any, error) {
+		inFlight.Done()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	du := NewDataUpdater("", nil)
+	du.Register(
+		&fakeSource{name: "a", key: "a", interval: time.Hour, fetch: blockUntilCancelled},
+		&fakeSource{name: "b", key: "b", interval: time.Hour, fetch: blockUntilCancelled},
+	)
+	du.TriggerUpdate()
+	inFlight.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, du.Shutdown(ctx))
+
+	data := du.GetData()
+	assert.Empty(t, data.LastUpdated)
+}
+
+func TestNoBackgroundUpdatesAfterShutdown(t *testing.T) {
+	src := &fakeSource{name: "a", key: "a", interval: time.Hour, fetch: returning(1, nil)}
+
+	du := NewDataUpdater("", nil)
+	du.Register(src)
+	assert.NoError(t, du.Shutdown(context.Background()))
+
+	du.TriggerUpdate()
+	du.GetData()
+
+	assert.NoError(t, du.Shutdown(context.Background()))
+	assert.Zero(t, src.calls.Load())
+}
+
+func TestFailingSourceBacksOff(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	src := &fakeSource{name: "weather", key: models.KeyWeather, interval: 10 * time.Minute, fetch: returning(nil, errors.New("breeze is down"))}
+
+	du := NewDataUpdater("", nil)
+	du.now = func() time.Time { return now }
+	du.Register(src)
+
+	du.UpdateIfStale(context.Background())
+	du.UpdateIfStale(context.Background())
+	assert.EqualValues(t, 1, src.calls.Load(), "a failed source isn't retried until its backoff elapses")
+
+	status := du.GetData().Sources[models.KeyWeather]
+	assert.Equal(t, "breeze is down", status.LastError)
+	assert.Equal(t, now, status.LastErrorAt)
+	assert.True(t, status.LastSuccess.IsZero())
+	assert.True(t, status.Stale)
+	assert.WithinRange(t, status.NextAttempt, now.Add(24*time.Second), now.Add(36*time.Second))
+
+	now = status.NextAttempt
+	du.UpdateIfStale(context.Background())
+	assert.EqualValues(t, 2, src.calls.Load())
+
+	status = du.GetData().Sources[models.KeyWeather]
+	assert.WithinRange(t, status.NextAttempt, now.Add(48*time.Second), now.Add(72*time.Second))
+
+	src.fetch = returning(&models.WeatherData{Location: "London"}, nil)
+	now = status.NextAttempt
+	du.UpdateIfStale(context.Background())
+
+	status = du.GetData().Sources[models.KeyWeather]
+	assert.Empty(t, status.LastError)
+	assert.Equal(t, now, status.LastSuccess)
+	assert.Equal(t, now.Add(10*time.Minute), status.NextAttempt)
+	assert.False(t, status.Stale)
+}
+
+func TestSourcesRefreshOnTheirOwnInterval(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	weather := &fakeSource{name: "weather", key: models.KeyWeather, interval: 10 * time.Minute, fetch: returning(nil, nil)}
+	repos := &fakeSource{name: "repos", key: models.KeyRepositories, interval: time.Hour, fetch: returning(nil, nil)}
+
+	du := NewDataUpdater("", nil)
+	du.now = func() time.Time { return now }
+	du.Register(weather, repos)
+
+	for range 6 {
+		du.UpdateIfStale(context.Background())
+		now = now.Add(10 * time.Minute)
+	}
+	du.UpdateIfStale(context.Background())
+
+	assert.EqualValues(t, 7, weather.calls.Load())
+	assert.EqualValues(t, 2, repos.calls.Load())
+	assert.False(t, du.GetData().Stale(models.KeyRepositories))
+}
+
+func TestBackoff(t *testing.T) {
+	for _, tc := range []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{8, maxBackoff},
+		{100, maxBackoff},
+	} {
+		got := backoff(tc.failures)
+		assert.InDelta(t, float64(tc.want), float64(got), float64(tc.want)/5, "failures=%d", tc.failures)
+	}
+}
+
+func TestRefreshDuringRunningUpdateIsNotLost(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	repos := &fakeSource{name: "repos", key: models.KeyRepositories, interval: time.Hour}
+	repos.fetch = func(context.Context) (any, error) {
+		once.Do(func() {
+			close(started)
+			<-release
+		})
+		return nil, nil
+	}
+	weather := &fakeSource{name: "weather", key: models.KeyWeather, interval: time.Hour, fetch: returning(nil, nil)}
+
+	du := NewDataUpdater("", nil)
+	du.Register(repos, weather)
+	du.TriggerUpdate()
+	<-started
+
+	du.Refresh(models.KeyRepositories)
+	close(release)
+
+	assert.Eventually(t, func() bool { return repos.calls.Load() == 2 }, time.Second, time.Millisecond)
+	assert.NoError(t, du.Shutdown(context.Background()))
+	assert.EqualValues(t, 1, weather.calls.Load())
+}
+
+type retryAtError struct{ at time.Time }
+
+func (e retryAtError) Error() string      { return "rate limited" }
+func (e retryAtError) RetryAt() time.Time { return e.at }
+
+func TestFailingSourceWaitsForRetryAt(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	reset := now.Add(45 * time.Minute)
+	src := &fakeSource{name: "repos", key: models.KeyRepositories, interval: time.Hour, fetch: returning(nil, fmt.Errorf("fetching: %w", retryAtError{reset}))}
+
+	du := NewDataUpdater("", nil)
+	du.now = func() time.Time { return now }
+	du.Register(src)
+	du.Update(context.Background())
+
+	assert.Equal(t, reset, du.GetData().Sources[models.KeyRepositories].NextAttempt)
+}
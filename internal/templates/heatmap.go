@@ -0,0 +1,96 @@
+package templates
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// Heatmap geometry, in SVG user units. Each week is a column of seven days,
+// Sunday at the top, with month names above and weekday names to the left.
+const (
+	heatmapCell   = 10
+	heatmapStep   = heatmapCell + 3
+	heatmapLeft   = 28
+	heatmapTop    = 16
+	heatmapMinGap = 3 // weeks between month labels, so they never overlap
+	heatmapRows   = 7
+)
+
+// heatmapSVG renders a contribution calendar as an inline SVG. Cells only
+// carry a level class, so their colours come from the theme's CSS variables
+// and follow dark and light mode.
+func heatmapSVG(cal *models.ContributionCalendar) template.HTML {
+	if cal == nil || len(cal.Weeks) == 0 {
+		return ""
+	}
+
+	width := heatmapLeft + len(cal.Weeks)*heatmapStep
+	height := heatmapTop + heatmapRows*heatmapStep
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg class="heatmap" viewBox="0 0 %d %d" width="%d" height="%d" role="img" aria-label="%s" xmlns="http://www.w3.org/2000/svg">`,
+		width, height, width, height, contributionsLabel(cal.Total, "in the last year"))
+	sb.WriteString("\n")
+
+	for _, l := range monthLabels(cal.Weeks) {
+		fmt.Fprintf(&sb, `<text class="heatmap-label" x="%d" y="%d">%s</text>`+"\n",
+			heatmapLeft+l.week*heatmapStep, heatmapTop-6, l.month.String()[:3])
+	}
+
+	for _, wd := range []time.Weekday{time.Monday, time.Wednesday, time.Friday} {
+		fmt.Fprintf(&sb, `<text class="heatmap-label" x="0" y="%d">%s</text>`+"\n",
+			heatmapTop+int(wd)*heatmapStep+heatmapCell-1, wd.String()[:3])
+	}
+
+	for i, week := range cal.Weeks {
+		for _, day := range week.Days {
+			level := min(max(day.Level, 0), 4)
+			fmt.Fprintf(&sb, `<rect class="heatmap-day level-%d" x="%d" y="%d" width="%d" height="%d" rx="2"><title>%s</title></rect>`+"\n",
+				level, heatmapLeft+i*heatmapStep, heatmapTop+int(day.Date.Weekday())*heatmapStep,
+				heatmapCell, heatmapCell, contributionsLabel(day.Count, "on "+day.Date.Format("Jan 2, 2006")))
+		}
+	}
+
+	sb.WriteString("</svg>")
+	// Everything above is built from numbers and dates, so it's safe.
+	return template.HTML(sb.String())
+}
+
+type monthLabel struct {
+	week  int
+	month time.Month
+}
+
+// monthLabels places a label over the first week of each month. The label
+// for a partial month at the start is dropped if it would crowd the next.
+func monthLabels(weeks []models.ContributionWeek) []monthLabel {
+	var labels []monthLabel
+	for i, week := range weeks {
+		if len(week.Days) == 0 {
+			continue
+		}
+		month := week.Days[0].Date.Month()
+		if len(labels) == 0 || labels[len(labels)-1].month != month {
+			labels = append(labels, monthLabel{week: i, month: month})
+		}
+	}
+	if len(labels) > 1 && labels[1].week-labels[0].week < heatmapMinGap {
+		labels = labels[1:]
+	}
+	return labels
+}
+
+func contributionsLabel(n int, suffix string) string {
+	switch n {
+	case 0:
+		return "No contributions " + suffix
+	case 1:
+		return "1 contribution " + suffix
+	default:
+		return fmt.Sprintf("%d contributions %s", n, suffix)
+	}
+}
@@ -0,0 +1,76 @@
+package templates
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// calendar builds weeks of contributions from from to to inclusive, with
+// counts cycling through counts.
+func calendar(from, to time.Time, counts ...int) *models.ContributionCalendar {
+	cal := &models.ContributionCalendar{}
+	var week models.ContributionWeek
+	i := 0
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Sunday && len(week.Days) > 0 {
+			cal.Weeks = append(cal.Weeks, week)
+			week = models.ContributionWeek{}
+		}
+		n := counts[i%len(counts)]
+		cal.Total += n
+		week.Days = append(week.Days, models.ContributionDay{Date: d, Count: n, Level: min(n, 4)})
+		i++
+	}
+	// The last week is the current one, which is usually partial.
+	if len(week.Days) > 0 {
+		cal.Weeks = append(cal.Weeks, week)
+	}
+	return cal
+}
+
+func TestHeatmapSVG(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := map[string]struct {
+		cal  *models.ContributionCalendar
+		days int
+		last string
+	}{
+		// Starts mid-week, crosses two month boundaries and ends mid-week,
+		// as the current week always does.
+		"partial-weeks": {calendar(date(2025, 1, 22), date(2025, 3, 12), 0, 1, 3, 0, 7, 2, 12), 50, "Mar 12, 2025"},
+		"quiet":         {calendar(date(2025, 6, 1), date(2025, 6, 14), 0), 14, "Jun 14, 2025"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := string(heatmapSVG(tc.cal))
+			assert.Equal(t, tc.days, strings.Count(got, `class="heatmap-day`), "every day counted in the total is drawn")
+			assert.Contains(t, got, "on "+tc.last+"</title>", "the current, partial week is drawn")
+
+			golden := filepath.Join("testdata", "heatmap", name+".svg")
+
+			if *update {
+				require.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err, "run go test -update to create it")
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
+
+func TestHeatmapSVGEmpty(t *testing.T) {
+	assert.Empty(t, heatmapSVG(nil))
+	assert.Empty(t, heatmapSVG(&models.ContributionCalendar{}))
+}
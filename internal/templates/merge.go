@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+// mirrorWindow is how close together the same activity on two forges must
+// be to count as one pushed to a mirror.
+const mirrorWindow = 10 * time.Minute
+
+// forgeResults collects the results of every source whose key has the given
+// base, in registration order, so the first forge registered wins when
+// merging. It must be called with du.mu held.
+func forgeResults[T any](du *DataUpdater, base string) [][]T {
+	var lists [][]T
+	seen := make(map[string]bool)
+	for _, src := range du.sources {
+		key := src.Key()
+		if models.BaseKey(key) != base || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if list, ok := du.results[key].([]T); ok {
+			lists = append(lists, list)
+		}
+	}
+	return lists
+}
+
+// mergeRepositories combines the repository lists from each forge. A
+// repository mirrored on several forges is shown once, from the first.
+// Pinned repositories come first, then the rest in sel's order, up to the
+// length of the longest list, since each forge already applied the same
+// selection. A single list is returned as is, in the forge's own order.
+func mergeRepositories(lists [][]models.Repository, sel forge.RepoSelection) []models.Repository {
+	switch len(lists) {
+	case 0:
+		return nil
+	case 1:
+		return lists[0]
+	}
+
+	var merged []models.Repository
+	seen := make(map[string]bool)
+	limit := 0
+	for _, list := range lists {
+		limit = max(limit, len(list))
+		for _, repo := range list {
+			name := strings.ToLower(repo.Name)
+			if !seen[name] {
+				seen[name] = true
+				merged = append(merged, repo)
+			}
+		}
+	}
+
+	slices.SortStableFunc(merged, func(a, b models.Repository) int {
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		return sel.Compare(a, b)
+	})
+	return merged[:min(len(merged), limit)]
+}
+
+// mergeActivities interleaves the activity from each forge, newest first. An
+// activity that appears on two forges within mirrorWindow, such as a push
+// to a mirrored repository, is shown once, from the first forge.
+func mergeActivities(lists [][]models.Activity) []models.Activity {
+	switch len(lists) {
+	case 0:
+		return nil
+	case 1:
+		return lists[0]
+	}
+
+	var merged []models.Activity
+	limit := 0
+	for _, list := range lists {
+		limit = max(limit, len(list))
+		for _, a := range list {
+			if !slices.ContainsFunc(merged, func(m models.Activity) bool { return mirrored(m, a) }) {
+				merged = append(merged, a)
+			}
+		}
+	}
+
+	slices.SortStableFunc(merged, func(a, b models.Activity) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return merged[:min(len(merged), limit)]
+}
+
+// mirrored reports whether a and b look like the same activity on two
+// forges. Owners often differ between forges, so only the repository's own
+// name is compared.
+func mirrored(a, b models.Activity) bool {
+	if a.Forge == b.Forge || a.Type != b.Type || a.Detail != b.Detail {
+		return false
+	}
+	if !strings.EqualFold(path.Base(a.RepoName), path.Base(b.RepoName)) {
+		return false
+	}
+	return a.CreatedAt.Sub(b.CreatedAt).Abs() <= mirrorWindow
+}
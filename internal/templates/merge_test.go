@@ -0,0 +1,82 @@
+package templates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+)
+
+func TestMergeRepositories(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2023, 1, n, 0, 0, 0, 0, time.UTC) }
+	github := []models.Repository{
+		{Forge: models.ForgeGitHub, Name: "site", UpdatedAt: day(2)},
+		{Forge: models.ForgeGitHub, Name: "breeze", UpdatedAt: day(4), Pinned: true},
+		{Forge: models.ForgeGitHub, Name: "cli", UpdatedAt: day(1)},
+	}
+	gitlab := []models.Repository{
+		{Forge: models.ForgeGitLab, Name: "Site", UpdatedAt: day(6)},
+		{Forge: models.ForgeGitLab, Name: "tool", UpdatedAt: day(5)},
+	}
+
+	merged := mergeRepositories([][]models.Repository{github, gitlab}, forge.DefaultRepoSelection)
+
+	assert.Equal(t, []models.Repository{
+		github[1],
+		gitlab[1],
+		github[0],
+	}, merged, "pinned first, mirrors shown once from the first forge, capped at the longest list")
+
+	assert.Equal(t, github, mergeRepositories([][]models.Repository{github}, forge.DefaultRepoSelection), "a single list keeps its order")
+	assert.Nil(t, mergeRepositories(nil, forge.DefaultRepoSelection))
+}
+
+func TestMergeRepositoriesKeepsConfiguredSort(t *testing.T) {
+	recently := time.Now()
+	github := []models.Repository{
+		{Forge: models.ForgeGitHub, Name: "site", Stars: 40, UpdatedAt: recently.Add(-48 * time.Hour)},
+		{Forge: models.ForgeGitHub, Name: "cli", Stars: 3, UpdatedAt: recently},
+	}
+	gitlab := []models.Repository{
+		{Forge: models.ForgeGitLab, Name: "tool", Stars: 12, UpdatedAt: recently.Add(-time.Hour)},
+		{Forge: models.ForgeGitLab, Name: "notes", Stars: 90, UpdatedAt: recently.Add(-72 * time.Hour)},
+	}
+
+	merged := mergeRepositories([][]models.Repository{github, gitlab}, forge.RepoSelection{Sort: forge.SortStars})
+
+	assert.Equal(t, []models.Repository{gitlab[1], github[0]}, merged, "most stars first across forges, not most recently updated")
+}
+
+func TestMergeActivities(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	github := []models.Activity{
+		{Forge: models.ForgeGitHub, Type: "PushEvent", RepoName: "joe/site", Detail: "main", CreatedAt: now},
+		{Forge: models.ForgeGitHub, Type: "IssuesEvent", RepoName: "joe/site", CreatedAt: now.Add(-3 * time.Hour)},
+	}
+	gitea := []models.Activity{
+		{Forge: models.ForgeGitea, Type: "PushEvent", RepoName: "jb/site", Detail: "main", CreatedAt: now.Add(time.Minute)},
+		{Forge: models.ForgeGitea, Type: "PushEvent", RepoName: "jb/dotfiles", CreatedAt: now.Add(-time.Hour)},
+	}
+
+	merged := mergeActivities([][]models.Activity{github, gitea})
+
+	assert.Equal(t, []models.Activity{github[0], gitea[1]}, merged)
+}
+
+func TestGetDataMergesForges(t *testing.T) {
+	github := []models.Repository{{Name: "site", UpdatedAt: time.Now().Add(-time.Hour)}}
+	gitlab := []models.Repository{{Forge: models.ForgeGitLab, Name: "tool", UpdatedAt: time.Now()}}
+
+	du := NewDataUpdater("", nil)
+	du.Register(
+		&fakeSource{name: "GitHub repositories", key: models.KeyRepositories, interval: time.Hour, fetch: returning(github, nil)},
+		&fakeSource{name: "GitLab repositories", key: models.ForgeKey(models.KeyRepositories, models.ForgeGitLab), interval: time.Hour, fetch: returning(gitlab, nil)},
+	)
+	du.Update(context.Background())
+
+	assert.Equal(t, []models.Repository{gitlab[0]}, du.GetData().Repos, "capped at the longest list")
+}
@@ -4,8 +4,10 @@ package templates
 import (
 	"fmt"
 	"html/template"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,16 +17,72 @@ import (
 )
 
 type PageData struct {
-	ProfileImage     string
-	GithubURL        string
-	LinkedInURL      string
-	BreezeURL        string
-	Email            string
-	IsDarkMode       bool
-	GithubRepos      []models.Repository
-	GitHubActivities []models.Activity
-	LastUpdated      string
-	Weather          *models.WeatherData
+	ProfileImage string
+	Links        []models.Link
+	IsDarkMode   bool
+	// Units is what the weather is shown in. The handler converts Weather
+	// to match before rendering.
+	Units models.Units
+	// Repos and Activities are merged from every forge.
+	Repos         []models.Repository
+	Activities    []models.Activity
+	Stats         *models.GitHubStats
+	Contributions *models.ContributionCalendar
+	LastUpdated   string
+	Weather       *models.WeatherData
+	// Results holds every source's latest value by key, so widgets without
+	// a dedicated field can be rendered with {{ index .Results "key" }}.
+	Results map[string]any
+	// Sources holds the refresh status of each source by result key.
+	Sources map[string]SourceStatus
+}
+
+// Stale reports whether the widget stored under key is showing old data.
+func (d PageData) Stale(key string) bool {
+	return d.Source(key).Stale
+}
+
+// Source returns the refresh status of the widget stored under key. For
+// widgets merged from several forges, such as "repositories", it's the
+// status of the first forge that's stale, if any.
+func (d PageData) Source(key string) SourceStatus {
+	status := d.Sources[key]
+	if status.Stale {
+		return status
+	}
+	for _, k := range slices.Sorted(maps.Keys(d.Sources)) {
+		if k != key && models.BaseKey(k) == key && d.Sources[k].Stale {
+			return d.Sources[k]
+		}
+	}
+	return status
+}
+
+// ReposHeading names the repository list after what's in it.
+func (d PageData) ReposHeading() string {
+	pinned := 0
+	for _, repo := range d.Repos {
+		if repo.Pinned {
+			pinned++
+		}
+	}
+	switch pinned {
+	case 0:
+		return "Recent Repositories"
+	case len(d.Repos):
+		return "Pinned Repositories"
+	default:
+		return "Repositories"
+	}
+}
+
+var funcs = template.FuncMap{
+	"formatDate": formatDate,
+	"timeSince":  timeSince,
+	"toLower":    strings.ToLower,
+	"forgeName":  models.ForgeName,
+	"compass":    compass,
+	"heatmap":    heatmapSVG,
 }
 
 type Renderer struct {
@@ -37,11 +95,7 @@ type Renderer struct {
 func NewRenderer() *Renderer {
 	tmplPath := filepath.Join("templates", "index.html")
 
-	tmpl, err := template.New("index.html").Funcs(template.FuncMap{
-		"formatDate": formatDate,
-		"timeSince":  timeSince,
-		"toLower":    strings.ToLower,
-	}).ParseFiles(tmplPath)
+	tmpl, err := template.New("index.html").Funcs(funcs).ParseFiles(tmplPath)
 	if err != nil {
 		logging.Error("Error parsing template", err)
 		os.Exit(1)
@@ -88,11 +142,7 @@ func (r *Renderer) watchTemplate() {
 		if info.ModTime().After(r.lastModTime) {
 			logging.Info("Template changed, reloading...")
 
-			newTmpl, err := template.New("index.html").Funcs(template.FuncMap{
-				"formatDate": formatDate,
-				"timeSince":  timeSince,
-				"toLower":    strings.ToLower,
-			}).ParseFiles(r.tmplPath)
+			newTmpl, err := template.New("index.html").Funcs(funcs).ParseFiles(r.tmplPath)
 			if err != nil {
 				logging.Error("Error reloading template", err)
 				continue
@@ -112,6 +162,13 @@ func formatDate(t time.Time) string {
 	return t.Format("Jan 02, 2006")
 }
 
+// compass names the eight-point compass direction of a bearing in degrees.
+func compass(degrees int) string {
+	points := []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+	d := (degrees%360 + 360) % 360
+	return points[(d+22)/45%8]
+}
+
 func timeSince(t time.Time) string {
 	now := time.Now()
 	diff := now.Sub(t)
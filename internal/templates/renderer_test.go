@@ -0,0 +1,226 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	t.Chdir("../..")
+	return NewRenderer()
+}
+
+func TestRenderTemplateMarksStaleWidgets(t *testing.T) {
+	r := newTestRenderer(t)
+
+	html, err := r.RenderTemplate(&PageData{
+		Repos:      []models.Repository{{Name: "repo1", UpdatedAt: time.Now()}},
+		Activities: []models.Activity{{RepoName: "user/repo1", CreatedAt: time.Now()}},
+		Sources: map[string]SourceStatus{
+			models.KeyRepositories: {Stale: true, LastSuccess: time.Now().Add(-3 * time.Hour), LastError: "GET http://breeze:8080/internal: connection refused"},
+			models.KeyActivity:     {LastSuccess: time.Now()},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, strings.Count(string(html), `class="stale-badge"`))
+	assert.Contains(t, string(html), `title="Last updated 3 hours ago"`)
+	assert.NotContains(t, string(html), "breeze:8080", "upstream errors aren't shown to visitors")
+}
+
+func TestRenderTemplateShowsActivityDetail(t *testing.T) {
+	r := newTestRenderer(t)
+
+	html, err := r.RenderTemplate(&PageData{
+		Activities: []models.Activity{{
+			RepoName:  "user/repo1",
+			CreatedAt: time.Now(),
+			Action:    "merged a pull request in",
+			URL:       "https://github.com/user/repo1",
+			Detail:    "#42 Add dark mode",
+			DetailURL: "https://github.com/user/repo1/pull/42",
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), `href="https://github.com/user/repo1/pull/42"`)
+	assert.Contains(t, string(html), "#42 Add dark mode")
+}
+
+func TestRenderTemplateShowsGroupedActivity(t *testing.T) {
+	r := newTestRenderer(t)
+
+	html, err := r.RenderTemplate(&PageData{
+		Activities: []models.Activity{{
+			RepoName:  "user/repo1",
+			CreatedAt: time.Now().Add(-2 * time.Hour),
+			Since:     time.Now().Add(-72 * time.Hour),
+			Count:     5,
+			Action:    "pushed commits to",
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), "×5")
+	assert.Contains(t, string(html), "3 days ago – 2 hours ago")
+}
+
+func TestRenderTemplateShowsPinnedRepositories(t *testing.T) {
+	r := newTestRenderer(t)
+
+	html, err := r.RenderTemplate(&PageData{
+		Repos: []models.Repository{{
+			Name:          "breeze",
+			Language:      "Go",
+			LanguageColor: "#00ADD8",
+			Topics:        []string{"weather"},
+			Pinned:        true,
+			UpdatedAt:     time.Now(),
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), "Pinned Repositories")
+	assert.Contains(t, string(html), "background-color: #00ADD8")
+	assert.Contains(t, string(html), "<li>weather</li>")
+}
+
+func TestRenderTemplateShowsForgeIcons(t *testing.T) {
+	r := newTestRenderer(t)
+
+	html, err := r.RenderTemplate(&PageData{
+		Repos: []models.Repository{
+			{Name: "site", UpdatedAt: time.Now()},
+			{Forge: models.ForgeGitLab, Name: "tool", UpdatedAt: time.Now()},
+		},
+		Activities: []models.Activity{{Forge: models.ForgeGitea, RepoName: "user/dotfiles", CreatedAt: time.Now()}},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), `icons.svg#icon-github`, "repositories without a forge are GitHub's")
+	assert.Contains(t, string(html), `aria-label="GitLab"`)
+	assert.Contains(t, string(html), `icons.svg#icon-gitlab`)
+	assert.Contains(t, string(html), `icons.svg#icon-gitea`)
+}
+
+func TestSourceMergesForges(t *testing.T) {
+	d := PageData{Sources: map[string]SourceStatus{
+		models.KeyRepositories: {Name: "GitHub repositories"},
+		models.ForgeKey(models.KeyRepositories, models.ForgeGitLab): {Name: "GitLab repositories", Stale: true},
+		models.KeyActivity: {Name: "GitHub activity"},
+	}}
+
+	assert.Equal(t, "GitLab repositories", d.Source(models.KeyRepositories).Name)
+	assert.True(t, d.Stale(models.KeyRepositories))
+	assert.False(t, d.Stale(models.KeyActivity))
+}
+
+func TestReposHeading(t *testing.T) {
+	pinned := models.Repository{Pinned: true}
+	recent := models.Repository{}
+
+	assert.Equal(t, "Recent Repositories", PageData{Repos: []models.Repository{recent}}.ReposHeading())
+	assert.Equal(t, "Pinned Repositories", PageData{Repos: []models.Repository{pinned}}.ReposHeading())
+	assert.Equal(t, "Repositories", PageData{Repos: []models.Repository{pinned, recent}}.ReposHeading())
+}
+
+func TestRenderTemplateShowsStats(t *testing.T) {
+	r := newTestRenderer(t)
+
+	html, err := r.RenderTemplate(&PageData{
+		Stats: &models.GitHubStats{
+			Languages:   []models.LanguageShare{{Name: "Go", Percent: 72.5}, {Name: "HTML", Percent: 27.5}},
+			PublicRepos: 12,
+			Stars:       34,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), `style="width: 72.5%"`)
+	assert.Contains(t, string(html), "<dd>34</dd>")
+}
+
+func TestRenderTemplateShowsContributions(t *testing.T) {
+	r := newTestRenderer(t)
+
+	day := time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)
+	html, err := r.RenderTemplate(&PageData{
+		Contributions: &models.ContributionCalendar{
+			Total: 7,
+			Weeks: []models.ContributionWeek{{Days: []models.ContributionDay{{Date: day, Count: 7, Level: 4}}}},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), `<svg class="heatmap"`)
+	assert.Contains(t, string(html), "7 contributions on Mar 16, 2025")
+}
+
+func TestRenderTemplateShowsWeatherDetails(t *testing.T) {
+	r := newTestRenderer(t)
+
+	zone := time.FixedZone("", 3600)
+	html, err := r.RenderTemplate(&PageData{
+		Weather: &models.WeatherData{
+			Location:      "London",
+			Temperature:   15.5,
+			FeelsLike:     14.2,
+			High:          17,
+			Low:           9.5,
+			Humidity:      72,
+			WindSpeed:     4.1,
+			WindDirection: 230,
+			Sunrise:       time.Date(2024, 4, 25, 5, 52, 0, 0, zone),
+			Sunset:        time.Date(2024, 4, 25, 20, 12, 0, 0, zone),
+			Condition:     "Clear",
+			Code:          models.WeatherClearNight,
+			Forecast: []models.DailyForecast{
+				{Date: time.Date(2024, 4, 26, 12, 0, 0, 0, zone), High: 14.6, Low: 8.1, Condition: "Rain", Code: models.WeatherRain},
+				{Date: time.Date(2024, 4, 27, 12, 0, 0, 0, zone), High: 12.3, Low: 7, Condition: "Clouds"},
+				{Date: time.Date(2024, 4, 28, 12, 0, 0, 0, zone), High: 13, Low: 6, Condition: "Clear"},
+				{Date: time.Date(2024, 4, 29, 12, 0, 0, 0, zone), High: 15, Low: 8, Condition: "Clear"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), "feels like 14°C")
+	assert.Contains(t, string(html), `<span class="forecast-day">Fri</span>`)
+	assert.Contains(t, string(html), "15° / 8°")
+	assert.Contains(t, string(html), `<span class="forecast-day">Sun</span>`)
+	assert.NotContains(t, string(html), `<span class="forecast-day">Mon</span>`, "only three days are shown")
+	assert.Contains(t, string(html), "H 17° L 10°")
+	assert.Contains(t, string(html), "72% humidity")
+	assert.Contains(t, string(html), "4 m/s SW")
+	assert.Contains(t, string(html), "Sunrise 05:52 · Sunset 20:12")
+	assert.Contains(t, string(html), `<use href="/static/icons/icons.svg#weather-clear-night"></use>`)
+	assert.Contains(t, string(html), `<use href="/static/icons/icons.svg#weather-rain"></use>`)
+	assert.Contains(t, string(html), `<use href="/static/icons/icons.svg#weather-cloudy"></use>`, "days without a code fall back to cloudy")
+	assert.NotContains(t, string(html), "openweathermap.org")
+}
+
+func TestRenderTemplateCreditsWeatherProvider(t *testing.T) {
+	r := newTestRenderer(t)
+
+	html, err := r.RenderTemplate(&PageData{
+		Weather: &models.WeatherData{Location: "London", Provider: "Open-Meteo", ProviderURL: "https://open-meteo.com"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `<a href="https://open-meteo.com" class="weather-provider-link">Open-Meteo</a>`)
+
+	html, err = r.RenderTemplate(&PageData{Weather: &models.WeatherData{Location: "London"}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(html), "Powered by", "weather restored from an older snapshot has no credit")
+}
+
+func TestCompass(t *testing.T) {
+	for degrees, want := range map[int]string{0: "N", 22: "N", 23: "NE", 90: "E", 230: "SW", 338: "N", 360: "N", -45: "NW"} {
+		assert.Equal(t, want, compass(degrees), "%d°", degrees)
+	}
+}
@@ -0,0 +1,184 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+)
+
+// snapshot is the on-disk form of the updater's last good data, so a restart
+// can serve the previous page straight away instead of waiting on every API.
+type snapshot struct {
+	SavedAt     time.Time                  `json:"saved_at"`
+	LastUpdated time.Time                  `json:"last_updated"`
+	Results     map[string]json.RawMessage `json:"results"`
+	// LastSuccess is keyed by source name.
+	LastSuccess map[string]time.Time `json:"last_success"`
+}
+
+// PersistTo restores the snapshot at path, if there is one, and saves a new
+// snapshot there after every refresh. Sources must be registered first so
+// their schedules can be restored. It reports whether a snapshot was loaded.
+func (du *DataUpdater) PersistTo(path string) (bool, error) {
+	du.mu.Lock()
+	du.snapshotPath = path
+	du.mu.Unlock()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return false, fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+
+	results := make(map[string]any, len(snap.Results))
+	for key, raw := range snap.Results {
+		v, err := decodeResult(key, raw)
+		if err != nil {
+			return false, fmt.Errorf("parsing snapshot %s: %s: %w", path, key, err)
+		}
+		if v != nil {
+			results[key] = v
+		}
+	}
+
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	for key, v := range results {
+		du.results[key] = v
+	}
+
+	for _, src := range du.sources {
+		lastSuccess, ok := snap.LastSuccess[src.Name()]
+		if !ok {
+			continue
+		}
+		st := du.state[src.Name()]
+		st.lastSuccess = lastSuccess
+		st.nextAttempt = lastSuccess.Add(src.Interval())
+	}
+
+	du.lastUpdated = snap.LastUpdated
+	du.data.LastUpdated = snap.LastUpdated.Format("Jan 02 2006 15:04:05")
+
+	logging.Info("Restored data snapshot from %s (saved %s)", path, snap.SavedAt.Format(time.RFC3339))
+	return true, nil
+}
+
+// decodeResult turns a snapshotted result back into the type its source
+// produces. Results for unknown keys are dropped and refetched. Keys for
+// other forges, made by models.ForgeKey, decode like GitHub's.
+func decodeResult(key string, raw json.RawMessage) (any, error) {
+	var v any
+	switch models.BaseKey(key) {
+	case models.KeyRepositories:
+		v = new([]models.Repository)
+	case models.KeyActivity:
+		v = new([]models.Activity)
+	case models.KeyWeather:
+		v = new(models.WeatherData)
+	case models.KeyStats:
+		v = new(models.GitHubStats)
+	case models.KeyContributions:
+		v = new(models.ContributionCalendar)
+	default:
+		return nil, nil
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case *[]models.Repository:
+		return *v, nil
+	case *[]models.Activity:
+		return *v, nil
+	default:
+		return v, nil
+	}
+}
+
+// saveSnapshot writes the current data to the snapshot path, if one is set.
+// The file is replaced atomically so a crash mid-write never leaves a
+// truncated snapshot behind.
+func (du *DataUpdater) saveSnapshot() error {
+	du.saveMu.Lock()
+	defer du.saveMu.Unlock()
+
+	du.mu.RLock()
+	path := du.snapshotPath
+	snap := snapshot{
+		SavedAt:     du.now(),
+		LastUpdated: du.lastUpdated,
+		Results:     make(map[string]json.RawMessage, len(du.results)),
+		LastSuccess: make(map[string]time.Time, len(du.sources)),
+	}
+	var err error
+	for key, v := range du.results {
+		if snap.Results[key], err = json.Marshal(v); err != nil {
+			break
+		}
+	}
+	for _, src := range du.sources {
+		if st := du.state[src.Name()]; !st.lastSuccess.IsZero() {
+			snap.LastSuccess[src.Name()] = st.lastSuccess
+		}
+	}
+	du.mu.RUnlock()
+
+	if path == "" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	b, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	return writeFileAtomic(path, b)
+}
+
+func writeFileAtomic(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
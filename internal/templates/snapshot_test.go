@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "snapshot.json")
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repos := []models.Repository{{Name: "repo1", Stars: 3, UpdatedAt: now.Add(-time.Hour)}}
+	activities := []models.Activity{{Type: "PushEvent", RepoName: "user/repo1", CreatedAt: now.Add(-time.Minute)}}
+	weather := &models.WeatherData{Location: "London", Temperature: 12.5, Condition: "Clouds", LastUpdated: now}
+	contributions := &models.ContributionCalendar{Total: 1, Weeks: []models.ContributionWeek{{Days: []models.ContributionDay{{Date: now, Count: 1, Level: 4}}}}}
+	gitlabRepos := []models.Repository{{Forge: models.ForgeGitLab, Name: "tool", UpdatedAt: now.Add(-2 * time.Hour)}}
+	stats := &models.GitHubStats{Languages: []models.LanguageShare{{Name: "Go", Bytes: 10, Percent: 100}}, Stars: 4}
+
+	newSources := func(fetch func(any) func(context.Context) (any, error)) []Source {
+		return []Source{
+			&fakeSource{name: "repos", key: models.KeyRepositories, interval: time.Hour, fetch: fetch(repos)},
+			&fakeSource{name: "gitlab", key: models.ForgeKey(models.KeyRepositories, models.ForgeGitLab), interval: time.Hour, fetch: fetch(gitlabRepos)},
+			&fakeSource{name: "activity", key: models.KeyActivity, interval: time.Hour, fetch: fetch(activities)},
+			&fakeSource{name: "weather", key: models.KeyWeather, interval: 10 * time.Minute, fetch: fetch(weather)},
+			&fakeSource{name: "stats", key: models.KeyStats, interval: time.Hour, fetch: fetch(stats)},
+			&fakeSource{name: "contributions", key: models.KeyContributions, interval: time.Hour, fetch: fetch(contributions)},
+		}
+	}
+
+	du := NewDataUpdater("", nil)
+	du.now = func() time.Time { return now }
+	du.Register(newSources(func(v any) func(context.Context) (any, error) { return returning(v, nil) })...)
+	restored, err := du.PersistTo(path)
+	require.NoError(t, err)
+	assert.False(t, restored)
+
+	du.Update(context.Background())
+	require.FileExists(t, path)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	restarted := NewDataUpdater("", nil)
+	restarted.now = func() time.Time { return now.Add(5 * time.Minute) }
+	offline := newSources(func(any) func(context.Context) (any, error) {
+		return func(context.Context) (any, error) {
+			t.Fatal("restored sources shouldn't be fetched")
+			return nil, nil
+		}
+	})
+	restarted.Register(offline...)
+	restored, err = restarted.PersistTo(path)
+	require.NoError(t, err)
+	assert.True(t, restored)
+
+	data := restarted.GetData()
+	assert.Equal(t, repos, data.Repos)
+	assert.Equal(t, gitlabRepos, data.Results[models.ForgeKey(models.KeyRepositories, models.ForgeGitLab)])
+	assert.Equal(t, activities, data.Activities)
+	assert.Equal(t, weather, data.Weather)
+	assert.Equal(t, stats, data.Stats)
+	assert.Equal(t, contributions, data.Contributions)
+	assert.Equal(t, "Jan 01 2025 12:00:00", data.LastUpdated)
+	assert.Equal(t, now, data.Sources[models.KeyWeather].LastSuccess)
+	assert.Equal(t, now.Add(10*time.Minute), data.Sources[models.KeyWeather].NextAttempt)
+	assert.False(t, data.Stale(models.KeyWeather))
+}
+
+func TestSnapshotSkipsFailedUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	du := NewDataUpdater("", nil)
+	du.Register(&fakeSource{name: "repos", key: models.KeyRepositories, interval: time.Hour, fetch: returning(nil, assert.AnError)})
+	_, err := du.PersistTo(path)
+	require.NoError(t, err)
+
+	du.Update(context.Background())
+
+	assert.NoFileExists(t, path)
+}
+
+func TestPersistToRejectsCorruptSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"results": {"repositories": {"not": "a list"}}}`), 0o644))
+
+	du := NewDataUpdater("", nil)
+	restored, err := du.PersistTo(path)
+
+	assert.Error(t, err)
+	assert.False(t, restored)
+	assert.Nil(t, du.GetData().Repos)
+}
@@ -0,0 +1,36 @@
+package templates
+
+import (
+	"context"
+	"time"
+)
+
+// Source is something DataUpdater fetches in the background and exposes on
+// PageData, such as GitHub repositories or the current weather.
+type Source interface {
+	// Name identifies the source in logs.
+	Name() string
+	// Key is the PageData.Results key the fetched value is stored under.
+	Key() string
+	// Interval is how long a fetched value is considered fresh.
+	Interval() time.Duration
+	// Fetch retrieves the latest value. Returning nil, nil keeps whatever
+	// value was previously stored. If the error has a RetryAt() time.Time
+	// method, such as a rate limit error, the source isn't retried before
+	// then.
+	Fetch(ctx context.Context) (any, error)
+}
+
+// SourceStatus reports how fresh a source's data is, for showing stale
+// widgets on the page. LastError can name internal hosts and echo upstream
+// responses, so the page only shows LastSuccess; the error is logged and
+// reported by the authenticated update status.
+type SourceStatus struct {
+	Name        string    `json:"name"`
+	LastSuccess time.Time `json:"last_success"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at"`
+	NextAttempt time.Time `json:"next_attempt"`
+	// Stale is set when the source hasn't succeeded within its interval.
+	Stale bool `json:"stale"`
+}
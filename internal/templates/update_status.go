@@ -0,0 +1,135 @@
+package templates
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// maxUpdateHistory is how many finished updates are kept for status polling.
+const maxUpdateHistory = 20
+
+// Update statuses.
+const (
+	UpdateRunning   = "running"
+	UpdateSucceeded = "succeeded"
+	UpdateFailed    = "failed"
+	UpdateCancelled = "cancelled"
+)
+
+// UpdateStatus describes a single run of the updater.
+type UpdateStatus struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	Errors     map[string]string `json:"errors,omitempty"`
+}
+
+type updateRecord struct {
+	mu   sync.Mutex
+	s    UpdateStatus
+	done chan struct{}
+}
+
+func (r *updateRecord) status() UpdateStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.s
+	if s.Errors != nil {
+		s.Errors = make(map[string]string, len(r.s.Errors))
+		for k, v := range r.s.Errors {
+			s.Errors[k] = v
+		}
+	}
+	return s
+}
+
+// UpdateStatus returns the status of a recent update by id.
+func (du *DataUpdater) UpdateStatus(id string) (UpdateStatus, bool) {
+	du.mu.RLock()
+	defer du.mu.RUnlock()
+
+	if du.current != nil && du.current.s.ID == id {
+		return du.current.status(), true
+	}
+	for _, rec := range du.history {
+		if rec.s.ID == id {
+			return rec.status(), true
+		}
+	}
+	return UpdateStatus{}, false
+}
+
+// startUpdate acquires the updating lock and records a new running update.
+// If an update is already running it returns that update's record instead.
+func (du *DataUpdater) startUpdate() (*updateRecord, bool) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	if !du.updating.TryLock() {
+		return du.current, false
+	}
+
+	du.current = &updateRecord{
+		s: UpdateStatus{
+			ID:        newUpdateID(),
+			Status:    UpdateRunning,
+			StartedAt: du.now(),
+		},
+		done: make(chan struct{}),
+	}
+	return du.current, true
+}
+
+// finishUpdate records the outcome of an update and releases the updating
+// lock. Updates that didn't do anything can be left out of the history.
+func (du *DataUpdater) finishUpdate(rec *updateRecord, errs map[string]error, keep bool) {
+	du.mu.Lock()
+
+	rec.mu.Lock()
+	finished := du.now()
+	rec.s.FinishedAt = &finished
+	rec.s.Status = UpdateSucceeded
+	if _, ok := errs[""]; ok {
+		rec.s.Status = UpdateCancelled
+	} else if len(errs) > 0 {
+		rec.s.Status = UpdateFailed
+	}
+	if len(errs) > 0 {
+		rec.s.Errors = make(map[string]string, len(errs))
+		for name, err := range errs {
+			if name == "" {
+				name = "update"
+			}
+			rec.s.Errors[name] = err.Error()
+		}
+	}
+	rec.mu.Unlock()
+
+	if keep {
+		du.history = append(du.history, rec)
+		if len(du.history) > maxUpdateHistory {
+			du.history = du.history[len(du.history)-maxUpdateHistory:]
+		}
+	}
+
+	du.current = nil
+	close(rec.done)
+	du.updating.Unlock()
+
+	// Pick up any sources invalidated by Refresh while this update ran.
+	pending := du.hasInvalidated()
+	du.mu.Unlock()
+
+	if pending {
+		du.goBackground(du.UpdateIfStale)
+	}
+}
+
+func newUpdateID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
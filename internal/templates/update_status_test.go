@@ -0,0 +1,101 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerUpdateCoalesces(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	src := &fakeSource{name: "slow", key: "slow", interval: time.Hour}
+	var once sync.Once
+	src.fetch = func(context.Context) (any, error) {
+		once.Do(func() { close(started) })
+		<-release
+		return 1, nil
+	}
+
+	du := NewDataUpdater("", nil)
+	du.Register(src)
+
+	first, coalesced := du.TriggerUpdate()
+	assert.False(t, coalesced)
+	assert.Equal(t, UpdateRunning, first.Status)
+	assert.NotEmpty(t, first.ID)
+	<-started
+
+	second, coalesced := du.TriggerUpdate()
+	assert.True(t, coalesced)
+	assert.Equal(t, first.ID, second.ID)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		s, ok := du.UpdateStatus(first.ID)
+		return ok && s.Status == UpdateSucceeded
+	}, time.Second, time.Millisecond)
+
+	s, _ := du.UpdateStatus(first.ID)
+	assert.NotNil(t, s.FinishedAt)
+	assert.Empty(t, s.Errors)
+	assert.EqualValues(t, 1, src.calls.Load())
+
+	third, coalesced := du.TriggerUpdate()
+	assert.False(t, coalesced)
+	assert.NotEqual(t, first.ID, third.ID)
+
+	assert.NoError(t, du.Shutdown(context.Background()))
+}
+
+func TestTriggerUpdateReportsFailures(t *testing.T) {
+	du := NewDataUpdater("", nil)
+	du.Register(
+		&fakeSource{name: "ok", key: "ok", interval: time.Hour, fetch: returning(1, nil)},
+		&fakeSource{name: "broken", key: "broken", interval: time.Hour, fetch: returning(nil, errors.New("boom"))},
+	)
+
+	status, _ := du.TriggerUpdate()
+	require.Eventually(t, func() bool {
+		s, _ := du.UpdateStatus(status.ID)
+		return s.Status != UpdateRunning
+	}, time.Second, time.Millisecond)
+
+	s, ok := du.UpdateStatus(status.ID)
+	require.True(t, ok)
+	assert.Equal(t, UpdateFailed, s.Status)
+	assert.Equal(t, map[string]string{"broken": "boom"}, s.Errors)
+}
+
+func TestTriggerUpdateAfterShutdown(t *testing.T) {
+	du := NewDataUpdater("", nil)
+	require.NoError(t, du.Shutdown(context.Background()))
+
+	status, _ := du.TriggerUpdate()
+
+	assert.Equal(t, UpdateCancelled, status.Status)
+}
+
+func TestUpdateStatusHistoryIsBounded(t *testing.T) {
+	du := NewDataUpdater("", nil)
+	du.Register(&fakeSource{name: "a", key: "a", interval: time.Hour, fetch: returning(1, nil)})
+
+	var ids []string
+	for range maxUpdateHistory + 1 {
+		du.Update(context.Background())
+		ids = append(ids, du.history[len(du.history)-1].s.ID)
+	}
+
+	_, ok := du.UpdateStatus(ids[0])
+	assert.False(t, ok)
+	_, ok = du.UpdateStatus(ids[len(ids)-1])
+	assert.True(t, ok)
+
+	_, ok = du.UpdateStatus("nonexistent")
+	assert.False(t, ok)
+}
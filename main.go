@@ -1,19 +1,35 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 
 	"github.com/joho/godotenv"
 	"github.com/josephburgess/joeburgess.dev/internal/api"
 	"github.com/josephburgess/joeburgess.dev/internal/config"
 	"github.com/josephburgess/joeburgess.dev/internal/logging"
+	"github.com/josephburgess/joeburgess.dev/internal/models"
+	"github.com/josephburgess/joeburgess.dev/internal/services/forge"
+	"github.com/josephburgess/joeburgess.dev/internal/services/gitea"
 	"github.com/josephburgess/joeburgess.dev/internal/services/github"
+	"github.com/josephburgess/joeburgess.dev/internal/services/gitlab"
 	"github.com/josephburgess/joeburgess.dev/internal/services/weather"
 	"github.com/josephburgess/joeburgess.dev/internal/templates"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	configPath := flag.String("config", "", "path to a YAML site config file (defaults to $CONFIG_FILE)")
+	flag.Parse()
+
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("No .env file found or error loading it. Using environment variables directly.")
@@ -22,31 +38,132 @@ func main() {
 	logger := logging.NewLogger()
 	defer logger.Sync()
 
-	cfg := config.Load()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		logging.Error("Failed to load configuration", err)
+		return 1
+	}
 	logging.Info("Configuration loaded")
+	logging.AddSecrets(
+		cfg.GitHub.Token,
+		cfg.GitHub.WebhookSecret,
+		cfg.GitLab.Token,
+		cfg.Gitea.Token,
+		cfg.Weather.APIKey,
+		cfg.API.Token,
+		cfg.API.HMACSecret,
+	)
+
+	userAgent := "joeburgess.dev (+" + cfg.Server.BaseURL + ")"
+	filter := forge.Filter{
+		Repos: forge.RepoSelection{
+			Include:       cfg.GitHub.Repos.Include,
+			Exclude:       cfg.GitHub.Repos.Exclude,
+			SkipForks:     cfg.GitHub.Repos.SkipForks,
+			SkipArchived:  cfg.GitHub.Repos.SkipArchived,
+			SkipTemplates: cfg.GitHub.Repos.SkipTemplates,
+			MinStars:      cfg.GitHub.Repos.MinStars,
+			Sort:          cfg.GitHub.Repos.Sort,
+			Count:         cfg.GitHub.Repos.Count,
+		},
+		HiddenEvents: cfg.GitHub.HiddenEvents,
+	}
 
-	githubService := github.NewClient(cfg.GithubUsername)
-	weatherService := weather.NewClient(cfg.WeatherAPIKey)
+	githubService := github.NewClient(
+		cfg.GitHub.Username,
+		github.WithToken(cfg.GitHub.Token),
+		github.WithBaseURL(cfg.GitHub.APIURL),
+		github.WithWebURL(cfg.GitHub.WebURL),
+		github.WithFilter(filter),
+		github.WithUserAgent(userAgent),
+	)
 
 	tmplRenderer := templates.NewRenderer()
-	dataUpdater := templates.NewDataUpdater(
-		githubService,
-		weatherService,
-		cfg.WeatherLocation,
-		cfg.ProfileImage,
-		cfg.GithubURL,
-		cfg.LinkedInURL,
-		cfg.BreezeURL,
-		cfg.Email,
+	dataUpdater := templates.NewDataUpdater(cfg.Profile.Image, cfg.Profile.Links)
+	dataUpdater.SetRepoSelection(filter.Repos)
+	dataUpdater.Register(
+		github.NewRepositoriesSource(githubService, cfg.GitHub.Repos.List, cfg.GitHub.ReposRefresh),
+		forge.NewActivitySource(githubService, cfg.GitHub.ActivityRefresh),
+		github.NewStatsSource(githubService, cfg.GitHub.StatsRefresh),
 	)
+	if cfg.GitHub.Token != "" {
+		dataUpdater.Register(github.NewContributionsSource(githubService, cfg.GitHub.ContributionsRefresh))
+	}
+	// Other forges are registered after GitHub so that, for repositories
+	// mirrored between them, GitHub's card is the one shown.
+	if cfg.GitLab.Username != "" {
+		gitlabService := gitlab.NewClient(
+			cfg.GitLab.Username,
+			gitlab.WithToken(cfg.GitLab.Token),
+			gitlab.WithBaseURL(cfg.GitLab.URL),
+			gitlab.WithFilter(filter),
+			gitlab.WithUserAgent(userAgent),
+		)
+		dataUpdater.Register(
+			forge.NewRepositoriesSource(gitlabService, cfg.GitLab.ReposRefresh),
+			forge.NewActivitySource(gitlabService, cfg.GitLab.ActivityRefresh),
+		)
+	}
+	if cfg.Gitea.Username != "" {
+		giteaService := gitea.NewClient(
+			cfg.Gitea.Username,
+			gitea.WithToken(cfg.Gitea.Token),
+			gitea.WithBaseURL(cfg.Gitea.URL),
+			gitea.WithFilter(filter),
+			gitea.WithUserAgent(userAgent),
+		)
+		dataUpdater.Register(
+			forge.NewRepositoriesSource(giteaService, cfg.Gitea.ReposRefresh),
+			forge.NewActivitySource(giteaService, cfg.Gitea.ActivityRefresh),
+		)
+	}
+	locations, err := weather.NewLocations(
+		filepath.Join(cfg.Server.DataDir, "location.json"),
+		models.Location{Name: cfg.Weather.Location},
+	)
+	if err != nil {
+		logging.Error("Failed to restore weather location", err)
+	}
+	weatherProvider, err := weather.New(weather.Config{
+		Provider:     cfg.Weather.Provider,
+		APIKey:       cfg.Weather.APIKey,
+		URL:          cfg.Weather.URL,
+		GeocodingURL: cfg.Weather.GeocodingURL,
+		BreezeLink:   cfg.Weather.BreezeURL,
+	})
+	if err != nil {
+		logging.Warn("Weather widget disabled: %v", err)
+	} else {
+		logging.Info("Weather from %s", weatherProvider.Name())
+		dataUpdater.Register(weather.NewSource(weatherProvider, locations, cfg.Weather.Refresh))
+	}
 
-	dataUpdater.Update()
+	restored, err := dataUpdater.PersistTo(filepath.Join(cfg.Server.DataDir, "snapshot.json"))
+	if err != nil {
+		logging.Error("Failed to restore data snapshot", err)
+	}
+	if !restored {
+		dataUpdater.Update(ctx)
+	}
+	dataUpdater.Start()
 
-	r := api.Setup(tmplRenderer, dataUpdater)
+	r := api.Setup(api.NewOptions(cfg), tmplRenderer, dataUpdater, locations)
 
-	logging.Info("Server starting on %s", cfg.ServerAddress)
-	if err := r.ListenAndServe(); err != nil {
-		logging.Error("Failed to start server", err)
-		os.Exit(1)
+	logging.Info("Server starting on %s", r.Addr)
+	serverErr := api.Run(ctx, r, cfg.Server.ShutdownTimeout, func(ctx context.Context) {
+		if err := dataUpdater.Shutdown(ctx); err != nil {
+			logging.Error("Data updates did not stop cleanly", err)
+		}
+	})
+
+	if serverErr != nil {
+		logging.Error("Server stopped with error", serverErr)
+		return 1
 	}
+
+	logging.Info("Server stopped")
+	return 0
 }